@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"kafctl/internal/services"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+func aclsCommand() *command {
+	return &command{
+		name:    "acls",
+		summary: "List, create and delete ACL bindings",
+		subcommands: []*command{
+			{name: "list", summary: "List the ACL bindings matching a filter", run: aclsList},
+			{name: "create", summary: "Create ACL bindings", run: aclsCreate},
+			{name: "delete", summary: "Delete the ACL bindings matching a filter", run: aclsDelete},
+		},
+	}
+}
+
+// aclFlags registers the fields of an ACL binding on fs.
+func aclFlags(fs *flag.FlagSet, spec *services.ACLSpec) {
+	fs.StringVar(&spec.Principal, "principal", "", "Principal, e.g. User:alice")
+	fs.StringVar(&spec.ResourceType, "resource-type", "", "Resource type: "+strings.Join(services.ACLResourceTypes, ", "))
+	fs.StringVar(&spec.Name, "resource", "", "Resource name, * for every resource of the type")
+	fs.StringVar(&spec.PatternType, "pattern", "", "Pattern type: "+strings.Join(services.ACLPatternTypes, ", "))
+	fs.StringVar(&spec.Host, "host", "", "Host the principal connects from")
+	fs.StringVar(&spec.Permission, "permission", "", "Permission: "+strings.Join(services.ACLPermissionTypes, ", "))
+}
+
+func aclsList(args []string) int {
+	var conn connOptions
+	var spec services.ACLSpec
+	fs := newFlagSet("acls list", "acls list [-principal p] [-resource-type t] [-resource name] [-pattern type] [-operation op] [flags]\n\n"+
+		"Every filter flag is optional; without any all ACL bindings are listed. -pattern match\n"+
+		"also lists the prefixed and wildcard bindings that apply to -resource.", &conn)
+	aclFlags(fs, &spec)
+	fs.StringVar(&spec.Operation, "operation", "", "Operation: "+strings.Join(services.ACLOperations, ", "))
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	filter, err := spec.Filter()
+	if err != nil {
+		return usageError(fs, "%v", err)
+	}
+
+	return withAdmin(&conn, "", func(admin services.IKafAdmin) int {
+		acls, err := admin.DescribeACLs(filter)
+		if err != nil {
+			return runError("listing ACLs", err)
+		}
+		printACLs(acls)
+		return ExitOK
+	})
+}
+
+func aclsCreate(args []string) int {
+	var conn connOptions
+	var spec services.ACLSpec
+	var operations stringList
+	fs := newFlagSet("acls create", "acls create -principal p -resource-type t -resource name -operation op [-pattern literal|prefixed] [-permission allow|deny] [-host h] [flags]\n\n"+
+		"-operation can be repeated to create one binding per operation. Pattern type defaults\n"+
+		"to literal, permission to allow and host to *.", &conn)
+	aclFlags(fs, &spec)
+	fs.Var(&operations, "operation", "Operation (repeatable): "+strings.Join(services.ACLOperations, ", "))
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if len(operations) == 0 {
+		return usageError(fs, "operation is required")
+	}
+
+	acls := make([]kafka.ACLBinding, 0, len(operations))
+	for _, op := range operations {
+		spec.Operation = op
+		acl, err := spec.Binding()
+		if err != nil {
+			return usageError(fs, "%v", err)
+		}
+		acls = append(acls, acl)
+	}
+
+	return withAdmin(&conn, "", func(admin services.IKafAdmin) int {
+		if err := admin.CreateACLs(acls); err != nil {
+			return runError("creating ACLs", err)
+		}
+		for _, acl := range acls {
+			fmt.Fprintf(stderr, "Created %s\n", services.FormatACL(acl))
+		}
+		return ExitOK
+	})
+}
+
+func aclsDelete(args []string) int {
+	var conn connOptions
+	var spec services.ACLSpec
+	var dryRun, yes bool
+	fs := newFlagSet("acls delete", "acls delete [-principal p] [-resource-type t] [-resource name] [-pattern type] [-operation op] [-dry-run] [-yes] [flags]\n\n"+
+		"Deletes every ACL binding matching the filter. At least -principal or -resource is\n"+
+		"required; the matching bindings are listed and confirmed before anything is deleted.", &conn)
+	aclFlags(fs, &spec)
+	fs.StringVar(&spec.Operation, "operation", "", "Operation: "+strings.Join(services.ACLOperations, ", "))
+	fs.BoolVar(&dryRun, "dry-run", false, "Only list the bindings that would be deleted")
+	fs.BoolVar(&yes, "yes", false, "Do not ask for confirmation")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if spec.Principal == "" && spec.Name == "" {
+		return usageError(fs, "principal or resource is required")
+	}
+	filter, err := spec.Filter()
+	if err != nil {
+		return usageError(fs, "%v", err)
+	}
+
+	return withAdmin(&conn, "", func(admin services.IKafAdmin) int {
+		acls, err := admin.DescribeACLs(filter)
+		if err != nil {
+			return runError("listing ACLs", err)
+		}
+		if len(acls) == 0 {
+			fmt.Fprintln(stderr, "No ACL bindings match the filter")
+			return ExitOK
+		}
+		printACLs(acls)
+		if dryRun {
+			fmt.Fprintf(stderr, "Dry run, %d bindings would be deleted\n", len(acls))
+			return ExitOK
+		}
+		if !yes && !confirm(fmt.Sprintf("Delete %d ACL bindings?", len(acls))) {
+			fmt.Fprintln(stderr, "Aborted, nothing was deleted")
+			return ExitError
+		}
+
+		deleted, err := admin.DeleteACLs(filter)
+		if err != nil {
+			return runError("deleting ACLs", err)
+		}
+		fmt.Fprintf(stderr, "Deleted %d ACL bindings\n", len(deleted))
+		return ExitOK
+	})
+}
+
+func printACLs(acls []kafka.ACLBinding) {
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PRINCIPAL\tPERMISSION\tOPERATION\tRESOURCE TYPE\tRESOURCE\tPATTERN\tHOST")
+	for _, acl := range acls {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", acl.Principal, acl.PermissionType, acl.Operation,
+			acl.Type, acl.Name, acl.ResourcePatternType, acl.Host)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"fmt"
+	"kafctl/internal/services"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+func brokersCommand() *command {
+	return &command{
+		name:    "brokers",
+		summary: "Describe the cluster, its brokers and their configs",
+		subcommands: []*command{
+			{name: "describe", summary: "Show cluster id, controller and brokers, or the configs of one broker", run: brokersDescribe},
+			{name: "diff", summary: "Show broker configs that differ between brokers", run: brokersDiff},
+		},
+	}
+}
+
+func brokersDescribe(args []string) int {
+	var conn connOptions
+	var broker int
+	var overrides bool
+	fs := newFlagSet("brokers describe", "brokers describe [-id <broker>] [-overrides] [flags]\n\n"+
+		"Without -id the cluster id, the controller and every broker are printed.\n"+
+		"With -id every config of that broker is printed with its value and source.", &conn)
+	fs.IntVar(&broker, "id", -1, "Broker to show the configs of")
+	fs.BoolVar(&overrides, "overrides", false, "Only print configs that are not defaults")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	return withAdmin(&conn, "", func(admin services.IKafAdmin) int {
+		if broker >= 0 {
+			configs, err := admin.DescribeBrokerConfig(broker)
+			if err != nil {
+				return runError("describing broker config", err)
+			}
+			tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(tw, "NAME\tVALUE\tSOURCE\tREAD-ONLY")
+			for _, c := range configs {
+				if overrides && c.Default {
+					continue
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%t\n", c.Name, formatConfigValue(c), c.Source, c.ReadOnly)
+			}
+			tw.Flush()
+			return ExitOK
+		}
+
+		cluster, err := admin.DescribeCluster()
+		if err != nil {
+			return runError("describing cluster", err)
+		}
+		controller := -1
+		if cluster.Controller != nil {
+			controller = cluster.Controller.ID
+		}
+		clusterID := "unknown"
+		if cluster.ClusterID != nil {
+			clusterID = *cluster.ClusterID
+		}
+		fmt.Fprintf(stdout, "Cluster ID: %s\nController: %d\n\n", clusterID, controller)
+
+		tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tHOST\tPORT\tRACK\tCONTROLLER")
+		for _, n := range cluster.Nodes {
+			rack := "-"
+			if n.Rack != nil && *n.Rack != "" {
+				rack = *n.Rack
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%d\t%s\t%t\n", n.ID, n.Host, n.Port, rack, n.ID == controller)
+		}
+		tw.Flush()
+		return ExitOK
+	})
+}
+
+func brokersDiff(args []string) int {
+	var conn connOptions
+	var all bool
+	fs := newFlagSet("brokers diff", "brokers diff [-all] [flags]\n\n"+
+		"Compares the configs of every broker and prints those with different values.", &conn)
+	fs.BoolVar(&all, "all", false, "Print every config, not only those that differ")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	return withAdmin(&conn, "", func(admin services.IKafAdmin) int {
+		cluster, err := admin.DescribeCluster()
+		if err != nil {
+			return runError("describing cluster", err)
+		}
+		ids := nodeIDs(cluster.Nodes)
+		configs, err := services.DescribeBrokerConfigs(admin, ids)
+		if err != nil {
+			return runError("describing broker configs", err)
+		}
+		diffs := services.DiffBrokerConfigs(ids, configs, all)
+		if len(diffs) == 0 {
+			fmt.Fprintf(stderr, "All %d brokers have the same configs\n", len(ids))
+			return ExitOK
+		}
+
+		tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(tw, "NAME\t%s\n", brokerHeaders(cluster.Nodes))
+		for _, d := range diffs {
+			values := make([]string, len(d.Values))
+			for i, v := range d.Values {
+				values[i] = v
+				if v == "" {
+					values[i] = "-"
+				}
+			}
+			mark := ""
+			if all && d.Differs {
+				mark = " *"
+			}
+			fmt.Fprintf(tw, "%s%s\t%s\n", d.Name, mark, strings.Join(values, "\t"))
+		}
+		tw.Flush()
+		return ExitOK
+	})
+}
+
+func brokerHeaders(nodes []kafka.Node) string {
+	headers := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		headers = append(headers, fmt.Sprintf("BROKER %d", n.ID))
+	}
+	return strings.Join(headers, "\t")
+}
@@ -0,0 +1,242 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"kafctl/internal/config"
+	"kafctl/internal/logger"
+	"kafctl/internal/services"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/term"
+)
+
+// Exit codes returned by every command.
+const (
+	ExitOK    = 0
+	ExitError = 1
+	ExitUsage = 2
+)
+
+// Output streams, replaceable for tests.
+var (
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+	stdin  io.Reader = os.Stdin
+)
+
+// stdinReader buffers stdin for every prompt, so that a line read ahead by
+// one prompt is not lost to the next. It is created on first use; tests that
+// replace stdin set it back to nil.
+var stdinReader *bufio.Reader
+
+func input() *bufio.Reader {
+	if stdinReader == nil {
+		stdinReader = bufio.NewReader(stdin)
+	}
+	return stdinReader
+}
+
+type command struct {
+	name        string
+	summary     string
+	run         func(args []string) int
+	subcommands []*command
+}
+
+// connOptions holds the connection flags shared by every command.
+type connOptions struct {
+	kafkaBroker string
+	groupId     string
+	configFile  string
+	enableSSL   bool
+}
+
+// stringList is a flag that can be repeated, collecting every value.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+func commands() []*command {
+	return []*command{
+		topicsCommand(),
+		brokersCommand(),
+		consumeCommand(),
+		exportCommand(),
+		produceCommand(),
+		generateCommand(),
+		groupsCommand(),
+		aclsCommand(),
+		usersCommand(),
+		viewCommand(),
+	}
+}
+
+// Run executes the command line and returns the process exit code. The
+// shared producer is flushed before returning.
+func Run(args []string) int {
+	defer services.CloseKafProducer()
+	return dispatch("kafctl", commands(), args)
+}
+
+func dispatch(prog string, cmds []*command, args []string) int {
+	if len(args) == 0 {
+		printCommands(stderr, prog, cmds)
+		return ExitUsage
+	}
+
+	name := args[0]
+	if name == "-h" || name == "-help" || name == "--help" || name == "help" {
+		printCommands(stdout, prog, cmds)
+		return ExitOK
+	}
+
+	for _, cmd := range cmds {
+		if cmd.name != name {
+			continue
+		}
+		if cmd.subcommands != nil {
+			return dispatch(prog+" "+name, cmd.subcommands, args[1:])
+		}
+		return cmd.run(args[1:])
+	}
+
+	fmt.Fprintf(stderr, "%s: unknown command %q\n\n", prog, name)
+	printCommands(stderr, prog, cmds)
+	return ExitUsage
+}
+
+func printCommands(w io.Writer, prog string, cmds []*command) {
+	fmt.Fprintf(w, "Usage: %s <command> [flags]\n\nCommands:\n", prog)
+
+	sorted := make([]*command, len(cmds))
+	copy(sorted, cmds)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].name < sorted[j].name })
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, cmd := range sorted {
+		fmt.Fprintf(tw, "  %s\t%s\n", cmd.name, cmd.summary)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\nRun '%s <command> -h' for details on a command.\n", prog)
+}
+
+// newFlagSet returns a flag set for a command with the connection flags
+// already registered.
+func newFlagSet(name, usage string, conn *connOptions) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	fs.StringVar(&conn.kafkaBroker, "kafkaBroker", "", "Kafka broker address")
+	fs.StringVar(&conn.kafkaBroker, "b", "", "Kafka broker address (shorthand)")
+
+	fs.StringVar(&conn.groupId, "groupId", "", "Kafka consumer group ID")
+	fs.StringVar(&conn.groupId, "g", "", "Kafka consumer group ID (shorthand)")
+
+	fs.BoolVar(&conn.enableSSL, "enableSSL", false, "Enable the SSL/SASL security configuration")
+	fs.BoolVar(&conn.enableSSL, "s", false, "Enable the SSL/SASL security configuration (shorthand)")
+
+	fs.StringVar(&conn.configFile, "config", "", "Path to SSL/SASL security configuration file")
+	fs.StringVar(&conn.configFile, "f", "", "Path to SSL/SASL security configuration file (shorthand)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: kafctl %s\n\nFlags:\n", usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses args into fs. It returns false together with the exit
+// code to use when the command should stop, e.g. after -h or a bad flag.
+func parseFlags(fs *flag.FlagSet, args []string) (int, bool) {
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return ExitOK, false
+	}
+	if err != nil {
+		return ExitUsage, false
+	}
+	return ExitOK, true
+}
+
+// usageError reports a usage problem for fs and returns ExitUsage.
+func usageError(fs *flag.FlagSet, format string, args ...any) int {
+	fmt.Fprintf(stderr, "Error: "+format+"\n\n", args...)
+	fs.Usage()
+	return ExitUsage
+}
+
+// runError reports a failed operation and returns ExitError.
+func runError(msg string, err error) int {
+	fmt.Fprintf(stderr, "Error: %s: %v\n", msg, err)
+	return ExitError
+}
+
+// confirm asks question on stderr and reports whether the answer read from
+// stdin is yes.
+func confirm(question string) bool {
+	fmt.Fprintf(stderr, "%s [y/N] ", question)
+	answer, _ := input().ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// readPassword prompts on stderr and reads the password from the terminal
+// without echo. Piped input is not prompted for, its first line is read.
+func readPassword(prompt string) (string, error) {
+	if f, ok := stdin.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		fmt.Fprint(stderr, prompt)
+		password, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(stderr)
+		if err != nil {
+			return "", fmt.Errorf("reading password: %w", err)
+		}
+		return string(password), nil
+	}
+	line, err := input().ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", fmt.Errorf("reading password: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// initConfig loads the app config file and applies the connection flags on top.
+func initConfig(conn *connOptions, topic, outputFile string, view bool) int {
+	err := config.InitConfig(conn.kafkaBroker, conn.groupId, conn.configFile, topic, outputFile, conn.enableSSL, view)
+	if err != nil {
+		logger.Error("Error initializing kafka config", "error", err)
+		return runError("initializing kafka config", err)
+	}
+	return ExitOK
+}
+
+// withAdmin initializes the config and runs fn with the shared admin client.
+func withAdmin(conn *connOptions, topic string, fn func(admin services.IKafAdmin) int) int {
+	if code := initConfig(conn, topic, "", false); code != ExitOK {
+		return code
+	}
+
+	admin, err := services.NewKafAdmin()
+	if err != nil {
+		return runError("initializing kafka admin", err)
+	}
+	defer admin.Close()
+
+	return fn(admin)
+}
@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PromptsShareStdin(t *testing.T) {
+
+	var errOut bytes.Buffer
+	oldIn, oldErr := stdin, stderr
+	stdin, stderr, stdinReader = strings.NewReader("y\nsecret\r\nno\n"), &errOut, nil
+	t.Cleanup(func() { stdin, stderr, stdinReader = oldIn, oldErr, nil })
+
+	// every prompt reads one line of the same piped input
+	assert.True(t, confirm("Delete the records?"))
+	password, err := readPassword("Password: ")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", password)
+	assert.False(t, confirm("Delete the records?"))
+	assert.Equal(t, "Delete the records? [y/N] Delete the records? [y/N] ", errOut.String())
+
+	_, err = readPassword("Password: ")
+	assert.ErrorContains(t, err, "reading password")
+}
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kafctl/internal/config"
+	"kafctl/internal/services"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+func consumeCommand() *command {
+	return &command{
+		name:    "consume",
+		summary: "Read messages from a topic to stdout",
+		run:     consume,
+	}
+}
+
+func consume(args []string) int {
+	var conn connOptions
+	var topic, format string
+	var latest int
+	var follow bool
+	var wf windowFlags
+	fs := newFlagSet("consume", "consume -t <topic> [-n count | window flags | -follow] [-format preset|template] [flags]\n\n"+
+		"With window flags exactly the messages between the given offsets or times are\n"+
+		"printed, followed by the number read from every partition on stderr. -filter\n"+
+		"prints only the messages of the window that match the expression.\n\n"+
+		"-follow prints new messages as they arrive until interrupted with Ctrl-C,\n"+
+		"starting with the next message produced. It takes -P, -filter and -limit, and\n"+
+		"-n to start with the last N of each partition.\n\n"+
+		"-format is one of "+strings.Join(outputPresets, ", ")+" or a Go template such as\n"+
+		"'{{.Partition}}:{{.Offset}} {{.Key}} {{.Value}}'. Templates see Topic, Partition,\n"+
+		"Offset, Timestamp, TimestampType, Key, Value, Headers (map) and HeaderList\n"+
+		"(Key, Value and Null of every header).", &conn)
+	fs.StringVar(&topic, "topic", "", "Kafka topic to consume from (mandatory)")
+	fs.StringVar(&topic, "t", "", "Kafka topic to consume from (mandatory, shorthand)")
+	fs.IntVar(&latest, "latest", 0, "Print only the latest N messages of every partition")
+	fs.IntVar(&latest, "n", 0, "Print only the latest N messages of every partition (shorthand)")
+	fs.BoolVar(&follow, "follow", false, "Keep printing new messages until interrupted")
+	fs.StringVar(&format, "format", PresetDefault, "Output format: a preset ("+strings.Join(outputPresets, ", ")+") or a Go template")
+	wf.register(fs)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if latest < 0 {
+		return usageError(fs, "latest must not be negative")
+	}
+	window, err := wf.window()
+	if err != nil {
+		return usageError(fs, "%v", err)
+	}
+	if follow && window.IsBounded() {
+		return usageError(fs, "-follow cannot be combined with offset or time bounds")
+	}
+	if !follow && latest > 0 && wf.isSet() {
+		return usageError(fs, "-n cannot be combined with window flags")
+	}
+	formatter, err := newMessageFormatter(format)
+	if err != nil {
+		return usageError(fs, "%v", err)
+	}
+
+	if code := initConfig(&conn, topic, "", false); code != ExitOK {
+		return code
+	}
+	if config.Topic == "" {
+		return usageError(fs, "topic is required")
+	}
+
+	consumer, err := services.NewConsumer()
+	if err != nil {
+		return runError("creating consumer", err)
+	}
+
+	if follow {
+		defer consumer.Close()
+		return followTopic(consumer, config.Topic, window, int64(latest), formatter)
+	}
+
+	if wf.isSet() {
+		defer consumer.Close()
+		res, err := consumer.ConsumeWindow(config.Topic, window)
+		if err != nil {
+			return runError("reading messages", err)
+		}
+		if err := printMessages(res.Messages, formatter); err != nil {
+			return runError("writing messages", err)
+		}
+		fmt.Fprintf(stderr, "Read %d messages from topic '%s'\n", len(res.Messages), res.Topic)
+		if res.Truncated {
+			fmt.Fprintf(stderr, "Stopped at the limit of %d messages\n", window.Limit)
+		}
+		printPartitionRanges(stderr, res.Partitions, "READ")
+		return ExitOK
+	}
+
+	if latest > 0 {
+		// GetLatestRecords closes the consumer itself.
+		msgs, err := consumer.GetLatestRecords(config.Topic, latest)
+		if err != nil {
+			return runError("reading messages", err)
+		}
+		if err := printMessages(msgs, formatter); err != nil {
+			return runError("writing messages", err)
+		}
+		return ExitOK
+	}
+
+	defer consumer.Close()
+	if err := consumer.ConsumeMessages(config.Topic); err != nil {
+		return runError("consuming messages", err)
+	}
+	return ExitOK
+}
+
+// errLimitReached ends following a topic once -limit messages are printed.
+var errLimitReached = errors.New("limit reached")
+
+// followTopic prints new messages until Ctrl-C or SIGTERM, or until the
+// window limit is reached.
+func followTopic(consumer services.IConsumer, topic string, window services.MessageWindow, last int64, formatter messageFormatter) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(stderr, "Following topic '%s', press Ctrl-C to stop\n", topic)
+	var printed int
+	opts := services.TailOptions{Partitions: window.Partitions, Last: last, Filter: window.Filter}
+	err := consumer.Tail(ctx, topic, opts, func(msg *kafka.Message) error {
+		if err := formatter(stdout, msg); err != nil {
+			return err
+		}
+		printed++
+		if window.Limit > 0 && printed >= window.Limit {
+			return errLimitReached
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errLimitReached) {
+		return runError("following topic", err)
+	}
+	fmt.Fprintf(stderr, "Printed %d messages from topic '%s'\n", printed, topic)
+	return ExitOK
+}
+
+func printMessages(msgs []*kafka.Message, formatter messageFormatter) error {
+	for _, msg := range msgs {
+		if err := formatter(stdout, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
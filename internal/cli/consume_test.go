@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"kafctl/internal/services"
+	"testing"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+// tailConsumer records the options of Tail and hands it msgs.
+type tailConsumer struct {
+	services.IConsumer
+	opts services.TailOptions
+	msgs []*kafka.Message
+}
+
+func (c *tailConsumer) Tail(ctx context.Context, topic string, opts services.TailOptions, handle func(msg *kafka.Message) error) error {
+	c.opts = opts
+	for _, msg := range c.msgs {
+		if err := handle(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func Test_FollowTopic(t *testing.T) {
+
+	var out, errOut bytes.Buffer
+	oldOut, oldErr := stdout, stderr
+	stdout, stderr = &out, &errOut
+	t.Cleanup(func() { stdout, stderr = oldOut, oldErr })
+
+	formatter, err := newMessageFormatter("{{.Value}}")
+	assert.NoError(t, err)
+	topic := "orders"
+	var msgs []*kafka.Message
+	for _, v := range []string{"a", "b", "c"} {
+		msgs = append(msgs, &kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic}, Value: []byte(v)})
+	}
+
+	// without -n following starts with the next message produced
+	consumer := &tailConsumer{msgs: msgs}
+	window := services.NewMessageWindow()
+	assert.Equal(t, ExitOK, followTopic(consumer, topic, window, 0, formatter))
+	assert.Equal(t, int64(0), consumer.opts.Last)
+	assert.Equal(t, "a\nb\nc\n", out.String())
+	assert.Contains(t, errOut.String(), "Printed 3 messages from topic 'orders'")
+
+	// -n starts with the last N messages, -limit stops following
+	out.Reset()
+	consumer = &tailConsumer{msgs: msgs}
+	window.Limit = 2
+	assert.Equal(t, ExitOK, followTopic(consumer, topic, window, 5, formatter))
+	assert.Equal(t, int64(5), consumer.opts.Last)
+	assert.Equal(t, "a\nb\n", out.String())
+}
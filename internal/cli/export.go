@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"fmt"
+	"kafctl/internal/config"
+	"kafctl/internal/services"
+	"strings"
+	"time"
+)
+
+func exportCommand() *command {
+	return &command{
+		name:    "export",
+		summary: "Export every message currently in a topic into a file",
+		run:     export,
+	}
+}
+
+func export(args []string) int {
+	var conn connOptions
+	var topic, outputFile, format string
+	var quiet bool
+	var wf windowFlags
+	fs := newFlagSet("export", "export -t <topic> -o <file> [-format jsonl|json|csv|avro] [window flags] [flags]\n\n"+
+		"Reads every partition up to the end offsets captured at start, then exits.\n"+
+		"The window flags restrict the export to partitions, offsets or a time range,\n"+
+		"-filter keeps only the messages matching an expression.\n"+
+		"Without -format the format is taken from the file extension, defaulting to jsonl.", &conn)
+	fs.StringVar(&topic, "topic", "", "Kafka topic to export (mandatory)")
+	fs.StringVar(&topic, "t", "", "Kafka topic to export (mandatory, shorthand)")
+	fs.StringVar(&outputFile, "outputFile", "", "File to write the messages to (mandatory)")
+	fs.StringVar(&outputFile, "o", "", "File to write the messages to (mandatory, shorthand)")
+	fs.StringVar(&format, "format", "", "Output format: "+strings.Join(services.Formats, ", "))
+	fs.BoolVar(&quiet, "quiet", false, "Do not report progress")
+	fs.BoolVar(&quiet, "q", false, "Do not report progress (shorthand)")
+	wf.register(fs)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	window, err := wf.window()
+	if err != nil {
+		return usageError(fs, "%v", err)
+	}
+
+	if code := initConfig(&conn, topic, outputFile, false); code != ExitOK {
+		return code
+	}
+	if config.Topic == "" || config.OutputFile == "" {
+		return usageError(fs, "topic and output file are required")
+	}
+
+	consumer, err := services.NewConsumer()
+	if err != nil {
+		return runError("creating consumer", err)
+	}
+	defer consumer.Close()
+
+	opts := services.ExportOptions{Topic: config.Topic, OutputFile: config.OutputFile, Format: format}
+	if wf.isSet() {
+		opts.Window = &window
+	}
+	if !quiet {
+		opts.Progress = printExportProgress
+	}
+
+	summary, err := consumer.ConsumeMessagesInFile(opts)
+	if !quiet && summary != nil {
+		fmt.Fprintln(stderr)
+	}
+	if summary != nil {
+		printExportSummary(summary)
+	}
+	if err != nil {
+		return runError("exporting messages", err)
+	}
+	return ExitOK
+}
+
+func printExportProgress(p services.RangeProgress) {
+	fmt.Fprintf(stderr, "\rScanned %d/%d messages, exported %d, %d/%d partitions done",
+		p.Read, p.Total, p.Matched, p.PartitionsDone, p.PartitionsAssigned)
+}
+
+func printExportSummary(s *services.ExportSummary) {
+	fmt.Fprintf(stdout, "Exported %d of %d scanned messages from topic '%s' to %s (%s) in %s\n\n",
+		s.Exported, s.Scanned, s.Topic, s.OutputFile, s.Format, s.Elapsed.Round(time.Millisecond))
+
+	printPartitionRanges(stdout, s.Partitions, "EXPORTED")
+}
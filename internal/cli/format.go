@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"kafctl/internal/services"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// Output presets of consume, anything else is taken as a text/template.
+const (
+	PresetDefault = "default"
+	PresetRaw     = "raw"
+	PresetJSON    = "json"
+	PresetPretty  = "pretty"
+)
+
+var outputPresets = []string{PresetDefault, PresetRaw, PresetJSON, PresetPretty}
+
+// messageView is the data a -format template is executed with. Key and Value
+// are the raw bytes as text, Headers holds the last value of every header key
+// and HeaderList every header in order.
+type messageView struct {
+	Topic         string
+	Partition     int32
+	Offset        int64
+	Timestamp     time.Time
+	TimestampType string
+	Key           string
+	Value         string
+	Headers       map[string]string
+	HeaderList    []headerView
+}
+
+// headerView is one header of a messageView with its value as text. Null
+// tells a header without value from one with an empty value.
+type headerView struct {
+	Key   string
+	Value string
+	Null  bool
+}
+
+func newMessageView(msg *kafka.Message) messageView {
+	view := messageView{
+		Partition:     msg.TopicPartition.Partition,
+		Offset:        int64(msg.TopicPartition.Offset),
+		Timestamp:     msg.Timestamp,
+		TimestampType: msg.TimestampType.String(),
+		Key:           string(msg.Key),
+		Value:         string(msg.Value),
+		Headers:       make(map[string]string, len(msg.Headers)),
+		HeaderList:    make([]headerView, 0, len(msg.Headers)),
+	}
+	if msg.TopicPartition.Topic != nil {
+		view.Topic = *msg.TopicPartition.Topic
+	}
+	for _, h := range msg.Headers {
+		view.Headers[h.Key] = string(h.Value)
+		view.HeaderList = append(view.HeaderList, headerView{Key: h.Key, Value: string(h.Value), Null: h.Value == nil})
+	}
+	return view
+}
+
+// messageFormatter writes one message to w.
+type messageFormatter func(w io.Writer, msg *kafka.Message) error
+
+// newMessageFormatter returns the formatter for a preset name or a Go
+// text/template like '{{.Partition}}:{{.Offset}} {{.Key}} {{.Value}}'.
+// Templates get a newline appended unless they end with one.
+func newMessageFormatter(format string) (messageFormatter, error) {
+	switch format {
+	case "", PresetDefault:
+		return writeDefault, nil
+	case PresetRaw:
+		return writeRaw, nil
+	case PresetJSON:
+		return writeJSON, nil
+	case PresetPretty:
+		return writePretty, nil
+	}
+
+	if !strings.Contains(format, "{{") {
+		return nil, fmt.Errorf("unknown format %q, expected one of %s or a Go template",
+			format, strings.Join(outputPresets, ", "))
+	}
+	if !strings.HasSuffix(format, "\n") {
+		format += "\n"
+	}
+	tmpl, err := template.New("format").Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+	}).Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid format template: %w", err)
+	}
+	return func(w io.Writer, msg *kafka.Message) error {
+		return tmpl.Execute(w, newMessageView(msg))
+	}, nil
+}
+
+func writeDefault(w io.Writer, msg *kafka.Message) error {
+	headers := ""
+	for _, header := range msg.Headers {
+		headers += fmt.Sprintf("%s: %s, ", header.Key, string(header.Value))
+	}
+	_, err := fmt.Fprintf(w, "Partition=%d, Offset=%d, Key=%s, TimeStamp=%s, Headers=%s\nMessage=%s\n\n",
+		msg.TopicPartition.Partition, msg.TopicPartition.Offset, string(msg.Key), msg.Timestamp, headers, string(msg.Value))
+	return err
+}
+
+func writeRaw(w io.Writer, msg *kafka.Message) error {
+	if _, err := w.Write(msg.Value); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// writeJSON writes the message as the same one-line record as a jsonl export.
+func writeJSON(w io.Writer, msg *kafka.Message) error {
+	data, err := json.Marshal(services.NewMessageRecord(msg))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// writePretty writes a readable block per message, indenting JSON values.
+func writePretty(w io.Writer, msg *kafka.Message) error {
+	var b strings.Builder
+	view := newMessageView(msg)
+	fmt.Fprintf(&b, "--- %s [%d] @ %d  %s\n", view.Topic, view.Partition, view.Offset,
+		view.Timestamp.UTC().Format(time.RFC3339Nano))
+	if msg.Key != nil {
+		fmt.Fprintf(&b, "Key: %s\n", view.Key)
+	}
+	for _, h := range msg.Headers {
+		fmt.Fprintf(&b, "%s: %s\n", h.Key, string(h.Value))
+	}
+
+	var indented bytes.Buffer
+	if json.Valid(msg.Value) && json.Indent(&indented, msg.Value, "", "  ") == nil {
+		b.Write(indented.Bytes())
+	} else {
+		b.Write(msg.Value)
+	}
+	b.WriteString("\n\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
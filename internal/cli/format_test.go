@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TemplateHeaderList(t *testing.T) {
+	format, err := newMessageFormatter(`{{range .HeaderList}}{{.Key}}={{if .Null}}null{{else}}"{{.Value}}"{{end}} {{end}}`)
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	err = format(&out, &kafka.Message{Headers: []kafka.Header{
+		{Key: "trace", Value: []byte("abc")},
+		{Key: "empty", Value: []byte{}},
+		{Key: "gone"},
+		{Key: "trace", Value: []byte("def")},
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, `trace="abc" empty="" gone=null trace="def" `+"\n", out.String())
+}
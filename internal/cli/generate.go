@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"kafctl/internal/config"
+	"kafctl/internal/services"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// Messages rendered by -dry-run without -count
+const generateDryRunCount = 10
+
+func generateCommand() *command {
+	return &command{
+		name:    "generate",
+		summary: "Publish synthetic messages rendered from a template",
+		run:     generate,
+	}
+}
+
+func generate(args []string) int {
+	var conn connOptions
+	var topic, keyTemplate, valueTemplate, templateFile, partitioner string
+	var headers headerFlag
+	var partition int
+	var count int64
+	var duration time.Duration
+	var rate float64
+	var concurrency int
+	var seed uint64
+	var dryRun bool
+	fs := newFlagSet("generate", "generate -t <topic> -template <template> | -template-file <file> [-key-template template] [-count n] [-duration d] [-rate n] [-concurrency n] [-H key=value...] [-P partition | -partitioner name] [-seed n] [-dry-run] [flags]\n\n"+
+		"Renders every message from Go templates with these functions:\n"+
+		"  uuid, randInt min max, randFloat min max, randString n, randBool, pick a b ...,\n"+
+		"  seq [start] (number of the message from 1 or start), now, timestamp, unixMs, json v\n"+
+		"The value template can use the rendered key as {{.Key}}. Without -key-template\n"+
+		"messages have no key. Publishing stops after -count messages or -duration,\n"+
+		"Ctrl-C stops it in any case. The throughput is printed every second.\n"+
+		"-seed makes the random values repeatable, -dry-run prints the messages instead.", &conn)
+	fs.StringVar(&topic, "topic", "", "Kafka topic to publish to (mandatory)")
+	fs.StringVar(&topic, "t", "", "Kafka topic to publish to (mandatory, shorthand)")
+	fs.StringVar(&keyTemplate, "key-template", "", "Template of the message key (default no key)")
+	fs.StringVar(&valueTemplate, "template", "", "Template of the message value")
+	fs.StringVar(&templateFile, "template-file", "", "File with the template of the message value")
+	fs.Int64Var(&count, "count", 0, "Number of messages to publish (default no limit)")
+	fs.DurationVar(&duration, "duration", 0, "How long to publish, e.g. 30s or 5m (default no limit)")
+	fs.Float64Var(&rate, "rate", 0, "Target messages per second (default as fast as possible)")
+	fs.IntVar(&concurrency, "concurrency", 0, "Maximum messages waiting for delivery (default no limit)")
+	fs.Var(&headers, "header", "Message header as key=value, key:base64=..., key:hex=... or key:null (repeatable)")
+	fs.Var(&headers, "H", "Message header (repeatable, shorthand)")
+	fs.IntVar(&partition, "partition", -1, "Partition to publish to (default chosen by the partitioner)")
+	fs.IntVar(&partition, "P", -1, "Partition to publish to (shorthand)")
+	fs.StringVar(&partitioner, "partitioner", "", "Partitioner of the key: "+strings.Join(services.Partitioners, ", "))
+	fs.Uint64Var(&seed, "seed", 0, "Seed of the random values (default random)")
+	fs.BoolVar(&dryRun, "dry-run", false, fmt.Sprintf("Print the messages without publishing them (default -count %d)", generateDryRunCount))
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if templateFile != "" {
+		if valueTemplate != "" {
+			return usageError(fs, "-template and -template-file cannot be combined")
+		}
+		data, err := os.ReadFile(templateFile)
+		if err != nil {
+			return usageError(fs, "%v", err)
+		}
+		valueTemplate = strings.TrimRight(string(data), "\r\n")
+	}
+	if valueTemplate == "" {
+		return usageError(fs, "-template or -template-file is required")
+	}
+	if count < 0 || duration < 0 || rate < 0 || concurrency < 0 {
+		return usageError(fs, "-count, -duration, -rate and -concurrency must not be negative")
+	}
+
+	gen, err := services.NewGenerator(keyTemplate, valueTemplate, seed)
+	if err != nil {
+		return usageError(fs, "%v", err)
+	}
+	if dryRun {
+		if count == 0 {
+			count = generateDryRunCount
+		}
+		return printGenerated(gen, count)
+	}
+
+	if code := initConfig(&conn, topic, "", false); code != ExitOK {
+		return code
+	}
+	if config.Topic == "" {
+		return usageError(fs, "topic is required")
+	}
+	if partition >= 0 && partitioner != "" {
+		return usageError(fs, "-P and -partitioner cannot be combined")
+	}
+	if partitioner != "" && !slices.Contains(services.Partitioners, partitioner) {
+		return usageError(fs, "unknown partitioner '%s', expected one of %s", partitioner, strings.Join(services.Partitioners, ", "))
+	}
+	var h []kafka.Header
+	if len(headers) > 0 {
+		h, err = services.KafkaHeaders(headers)
+		if err != nil {
+			return usageError(fs, "%v", err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	summary, err := services.GenerateMessages(ctx, services.GenerateOptions{
+		Topic:         config.Topic,
+		KeyTemplate:   keyTemplate,
+		ValueTemplate: valueTemplate,
+		Headers:       h,
+		Partition:     int32(partition),
+		Partitioner:   partitioner,
+		Count:         count,
+		Duration:      duration,
+		Seed:          seed,
+		Limits:        services.ProduceLimits{Rate: rate, InFlight: concurrency},
+		Progress: func(p services.GenerateProgress) {
+			fmt.Fprintf(stderr, "%6s  sent %d  delivered %d  failed %d  %.0f msg/s\n",
+				p.Elapsed.Round(time.Second), p.Generated, p.Delivered, p.Failed, p.Rate)
+		},
+	})
+	if summary != nil {
+		rate := 0.0
+		if summary.Elapsed > 0 {
+			rate = float64(summary.Delivered) / summary.Elapsed.Seconds()
+		}
+		fmt.Fprintf(stdout, "Sent %d messages, delivered %d, failed %d in %s (%.0f msg/s)\n", summary.Delivered+summary.Failed,
+			summary.Delivered, summary.Failed, summary.Elapsed.Round(time.Millisecond), rate)
+		printDeliveredPartitions(summary.Partitions)
+	}
+	if err != nil {
+		return runError("generating messages", err)
+	}
+	if summary.Failed > 0 {
+		return runError("generating messages", fmt.Errorf("%d message(s) were not delivered: %v", summary.Failed, summary.FirstError))
+	}
+	return ExitOK
+}
+
+// printGenerated prints count messages of gen, one per line with the key
+// before a tab.
+func printGenerated(gen *services.Generator, count int64) int {
+	for i := int64(0); i < count; i++ {
+		msg, err := gen.Next()
+		if err != nil {
+			return runError(fmt.Sprintf("rendering message %d", i+1), err)
+		}
+		if msg.Key != nil {
+			fmt.Fprintf(stdout, "%s\t%s\n", *msg.Key, msg.Value)
+		} else {
+			fmt.Fprintln(stdout, msg.Value)
+		}
+	}
+	return ExitOK
+}
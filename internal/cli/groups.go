@@ -0,0 +1,387 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"kafctl/internal/config"
+	"kafctl/internal/services"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+func groupsCommand() *command {
+	return &command{
+		name:    "groups",
+		summary: "List, describe, reset and delete consumer groups and show their lag",
+		subcommands: []*command{
+			{name: "list", summary: "List all consumer groups", run: groupsList},
+			{name: "describe", summary: "Show state, members, assignments and committed offsets of a group", run: groupsDescribe},
+			{name: "lag", summary: "Show the lag of groups per partition, topic or group", run: groupsLag},
+			{name: "reset-offsets", summary: "Reset the committed offsets of a group, with a dry run", run: groupsResetOffsets},
+			{name: "delete", summary: "Delete groups by name, pattern or idle time", run: groupsDelete},
+		},
+	}
+}
+
+func groupsList(args []string) int {
+	var conn connOptions
+	fs := newFlagSet("groups list", "groups list [flags]", &conn)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	return withAdmin(&conn, "", func(admin services.IKafAdmin) int {
+		groups, err := admin.ListConsumerGroups()
+		if err != nil {
+			return runError("listing consumer groups", err)
+		}
+
+		tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "GROUP\tSTATE\tTYPE\tSIMPLE")
+		for _, g := range groups {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%t\n", g.GroupID, g.State, g.Type, g.IsSimpleConsumerGroup)
+		}
+		tw.Flush()
+		return ExitOK
+	})
+}
+
+func groupsDescribe(args []string) int {
+	var conn connOptions
+	fs := newFlagSet("groups describe", "groups describe -g <group> [flags]\n\n"+
+		"The group defaults to the groupId of the app config.", &conn)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	return withAdmin(&conn, "", func(admin services.IKafAdmin) int {
+		group := config.GroupId
+		if group == "" {
+			return usageError(fs, "group is required")
+		}
+
+		groups, err := admin.DescribeConsumerGroups([]string{group})
+		if err != nil {
+			return runError("describing consumer group", err)
+		}
+		if len(groups) == 0 {
+			return runError("describing consumer group", fmt.Errorf("group '%s' not found", group))
+		}
+		g := groups[0]
+		if g.Error.Code() != kafka.ErrNoError {
+			return runError("describing consumer group "+group, g.Error)
+		}
+
+		fmt.Fprintf(stdout, "Group: %s\nState: %s\nAssignor: %s\nCoordinator: %s\nMembers: %d\n\n",
+			g.GroupID, g.State, g.PartitionAssignor, formatNode(g.Coordinator), len(g.Members))
+
+		if len(g.Members) > 0 {
+			tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(tw, "MEMBER\tCLIENT\tHOST\tASSIGNMENT")
+			for _, m := range g.Members {
+				assignment := services.FormatAssignment(m.Assignment.TopicPartitions)
+				if assignment == "" {
+					assignment = "-"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", m.ConsumerID, m.ClientID, m.Host, assignment)
+			}
+			tw.Flush()
+			fmt.Fprintln(stdout)
+		}
+
+		offsets, err := admin.ListConsumerGroupOffsets(group)
+		if err != nil {
+			return runError("listing committed offsets", err)
+		}
+		tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "TOPIC\tPARTITION\tCOMMITTED")
+		for _, tp := range offsets {
+			fmt.Fprintf(tw, "%s\t%d\t%s\n", *tp.Topic, tp.Partition, tp.Offset)
+		}
+		tw.Flush()
+		return ExitOK
+	})
+}
+
+// Aggregation levels of groups lag
+const (
+	lagByPartition = "partition"
+	lagByTopic     = "topic"
+	lagByGroup     = "group"
+)
+
+func groupsLag(args []string) int {
+	var conn connOptions
+	var topic, by, sortBy string
+	var all bool
+	var minLag int64
+	fs := newFlagSet("groups lag", "groups lag [-g group | -all] [-t topic] [-by level] [-sort key] [-min-lag N] [flags]\n\n"+
+		"Lag is the high watermark minus the committed offset of a partition. Partitions\n"+
+		"without a committed offset count every retained message. The group defaults to\n"+
+		"the groupId of the app config, -all reports every group of the cluster.", &conn)
+	fs.StringVar(&topic, "topic", "", "Only report partitions of this topic")
+	fs.StringVar(&topic, "t", "", "Only report partitions of this topic (shorthand)")
+	fs.BoolVar(&all, "all", false, "Report all consumer groups")
+	fs.StringVar(&by, "by", lagByPartition, "Sum lag per partition, topic or group")
+	fs.StringVar(&sortBy, "sort", services.LagSortLag, "Sort partitions by "+strings.Join(services.LagSortKeys, ", ")+"; lag sorts largest first")
+	fs.Int64Var(&minLag, "min-lag", 0, "Only show rows with at least this lag")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if !slices.Contains([]string{lagByPartition, lagByTopic, lagByGroup}, by) {
+		return usageError(fs, "unknown -by '%s'", by)
+	}
+	if !slices.Contains(services.LagSortKeys, sortBy) {
+		return usageError(fs, "unknown -sort '%s'", sortBy)
+	}
+	if all && conn.groupId != "" {
+		return usageError(fs, "-all cannot be combined with -g")
+	}
+
+	return withAdmin(&conn, "", func(admin services.IKafAdmin) int {
+		var groups []string
+		if all {
+			listings, err := admin.ListConsumerGroups()
+			if err != nil {
+				return runError("listing consumer groups", err)
+			}
+			for _, g := range listings {
+				groups = append(groups, g.GroupID)
+			}
+		} else {
+			if config.GroupId == "" {
+				return usageError(fs, "group is required, pass -g or -all")
+			}
+			groups = []string{config.GroupId}
+		}
+
+		consumer, err := services.NewConsumer()
+		if err != nil {
+			return runError("creating consumer", err)
+		}
+		defer consumer.Close()
+
+		lags, err := services.NewLagService(admin, consumer).GroupLag(groups, topic)
+		if err != nil {
+			return runError("computing consumer lag", err)
+		}
+
+		tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		switch by {
+		case lagByTopic:
+			fmt.Fprintln(tw, "GROUP\tTOPIC\tPARTITIONS\tLAG")
+			for _, l := range services.LagByTopic(lags) {
+				if l.Lag >= minLag {
+					fmt.Fprintf(tw, "%s\t%s\t%d\t%d\n", l.Group, l.Topic, l.Partitions, l.Lag)
+				}
+			}
+		case lagByGroup:
+			fmt.Fprintln(tw, "GROUP\tTOPICS\tPARTITIONS\tLAG")
+			for _, l := range services.LagByGroup(lags) {
+				if l.Lag >= minLag {
+					fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", l.Group, l.Topics, l.Partitions, l.Lag)
+				}
+			}
+		default:
+			lags = services.FilterPartitionLags(lags, minLag)
+			services.SortPartitionLags(lags, sortBy, sortBy == services.LagSortLag)
+			fmt.Fprintln(tw, "GROUP\tTOPIC\tPARTITION\tCOMMITTED\tEND\tLAG")
+			for _, l := range lags {
+				fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%d\t%d\n", l.Group, l.Topic, l.Partition, formatCommitted(l.Committed), l.EndOffset, l.Lag)
+			}
+		}
+		tw.Flush()
+		return ExitOK
+	})
+}
+
+func groupsResetOffsets(args []string) int {
+	var conn connOptions
+	var topic, datetime string
+	var toEarliest, toLatest, dryRun, yes bool
+	var shiftBy, toOffset int64
+	fs := newFlagSet("groups reset-offsets", "groups reset-offsets -g <group> [-t topic] <mode> [-dry-run] [-yes] [flags]\n\n"+
+		"Exactly one mode is required: -to-earliest, -to-latest, -to-datetime, -shift-by\n"+
+		"or -to-offset. Without -t every partition the group committed to is reset.\n"+
+		"Targets are kept between the earliest and latest offset of each partition and\n"+
+		"the group must not have active members. The current and target offset of every\n"+
+		"partition are printed before asking for confirmation; -dry-run stops there.\n"+
+		"The group is never taken from the app config, -g is required.", &conn)
+	fs.StringVar(&topic, "topic", "", "Only reset partitions of this topic")
+	fs.StringVar(&topic, "t", "", "Only reset partitions of this topic (shorthand)")
+	fs.BoolVar(&toEarliest, services.ResetToEarliest, false, "Reset to the earliest offset")
+	fs.BoolVar(&toLatest, services.ResetToLatest, false, "Reset to the latest offset")
+	fs.StringVar(&datetime, services.ResetToDatetime, "", "Reset to the first offset at or after a time (RFC 3339, 'YYYY-MM-DD hh:mm:ss' UTC or Unix ms)")
+	fs.Int64Var(&shiftBy, services.ResetShiftBy, 0, "Move the committed offsets by N, negative to rewind")
+	fs.Int64Var(&toOffset, services.ResetToOffset, 0, "Reset to the offset N")
+	fs.BoolVar(&dryRun, "dry-run", false, "Print the plan without committing it")
+	fs.BoolVar(&yes, "yes", false, "Do not ask for confirmation")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	group := conn.groupId
+	if group == "" {
+		return usageError(fs, "-g is required")
+	}
+
+	var modes []string
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case services.ResetToEarliest, services.ResetToLatest, services.ResetToDatetime, services.ResetShiftBy, services.ResetToOffset:
+			modes = append(modes, f.Name)
+		}
+	})
+	if len(modes) != 1 {
+		return usageError(fs, "exactly one reset mode is required, got %d", len(modes))
+	}
+	spec := services.OffsetResetSpec{Mode: modes[0], Offset: toOffset, Shift: shiftBy}
+	if spec.Mode == services.ResetToDatetime {
+		t, err := services.ParseWindowTime(datetime)
+		if err != nil {
+			return usageError(fs, "%v", err)
+		}
+		spec.Time = t
+	}
+
+	return withAdmin(&conn, "", func(admin services.IKafAdmin) int {
+		plan, err := admin.PlanOffsetReset(group, topic, spec)
+		if err != nil {
+			return runError("planning offset reset", err)
+		}
+
+		tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "TOPIC\tPARTITION\tCURRENT\tTARGET\tCHANGE")
+		for _, r := range plan {
+			change := "-"
+			if r.Current >= 0 {
+				change = fmt.Sprintf("%+d", r.Target-r.Current)
+			}
+			fmt.Fprintf(tw, "%s\t%d\t%s\t%d\t%s\n", r.Topic, r.Partition, formatCommitted(r.Current), r.Target, change)
+		}
+		tw.Flush()
+
+		if dryRun {
+			fmt.Fprintf(stderr, "Dry run, offsets of group %s were not changed\n", group)
+			return ExitOK
+		}
+		if !yes && !confirm(fmt.Sprintf("Reset %d partitions of group %s?", len(plan), group)) {
+			fmt.Fprintln(stderr, "Aborted, offsets were not changed")
+			return ExitError
+		}
+		if err := admin.ResetConsumerGroupOffsets(group, plan); err != nil {
+			return runError("resetting offsets", err)
+		}
+		fmt.Fprintf(stderr, "Reset %d partitions of group %s %s\n", len(plan), group, spec)
+		return ExitOK
+	})
+}
+
+func groupsDelete(args []string) int {
+	var conn connOptions
+	var match string
+	var emptyFor int
+	var dryRun, yes bool
+	fs := newFlagSet("groups delete", "groups delete [-match regex] [-empty-for days] [-dry-run] [-yes] [flags] [group...]\n\n"+
+		"Deletes the named groups or the groups selected by -match and -empty-for after\n"+
+		"listing them and asking for confirmation.\n"+
+		"-empty-for selects groups without members that left a message older than N days\n"+
+		"unread and read none of the last N days; caught up groups and groups without\n"+
+		"committed offsets cannot be judged and are skipped. Groups with active members\n"+
+		"are refused.", &conn)
+	fs.StringVar(&match, "match", "", "Select groups whose id matches the regular expression")
+	fs.IntVar(&emptyFor, "empty-for", 0, "Select groups empty for more than N days")
+	fs.BoolVar(&dryRun, "dry-run", false, "Print the selected groups without deleting them")
+	fs.BoolVar(&yes, "yes", false, "Do not ask for confirmation")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if emptyFor < 0 {
+		return usageError(fs, "empty-for must not be negative")
+	}
+	selector := services.GroupSelector{EmptyFor: time.Duration(emptyFor) * 24 * time.Hour}
+	if match != "" {
+		pattern, err := regexp.Compile(match)
+		if err != nil {
+			return usageError(fs, "invalid -match: %v", err)
+		}
+		selector.Pattern = pattern
+	}
+	named := fs.Args()
+	if conn.groupId != "" {
+		named = append(named, conn.groupId)
+	}
+	selecting := selector.Pattern != nil || selector.EmptyFor > 0
+	if len(named) > 0 && selecting {
+		return usageError(fs, "group names cannot be combined with -match or -empty-for")
+	}
+	if len(named) == 0 && !selecting {
+		return usageError(fs, "name the groups or select them with -match or -empty-for")
+	}
+
+	return withAdmin(&conn, "", func(admin services.IKafAdmin) int {
+		groups := named
+		if selecting {
+			var err error
+			groups, err = admin.SelectConsumerGroups(selector)
+			if err != nil {
+				return runError("selecting consumer groups", err)
+			}
+		}
+		if len(groups) == 0 {
+			fmt.Fprintln(stderr, "No consumer groups selected")
+			return ExitOK
+		}
+
+		for _, g := range groups {
+			fmt.Fprintln(stdout, g)
+		}
+		if dryRun {
+			fmt.Fprintf(stderr, "Dry run, %d groups would be deleted\n", len(groups))
+			return ExitOK
+		}
+		if !yes && !confirm(fmt.Sprintf("Delete %d consumer groups?", len(groups))) {
+			fmt.Fprintln(stderr, "Aborted, nothing was deleted")
+			return ExitError
+		}
+
+		results, err := admin.DeleteConsumerGroups(groups)
+		if err != nil {
+			return runError("deleting consumer groups", err)
+		}
+		failed := 0
+		tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "GROUP\tRESULT")
+		for _, r := range results {
+			result := "deleted"
+			if r.Error.Code() != kafka.ErrNoError {
+				result = "not deleted: " + r.Error.Error()
+				failed++
+			}
+			fmt.Fprintf(tw, "%s\t%s\n", r.Group, result)
+		}
+		tw.Flush()
+		if failed > 0 {
+			return runError("deleting consumer groups", fmt.Errorf("%d of %d groups not deleted", failed, len(results)))
+		}
+		return ExitOK
+	})
+}
+
+func formatCommitted(offset int64) string {
+	if offset < 0 {
+		return "-"
+	}
+	return fmt.Sprint(offset)
+}
+
+func formatNode(n kafka.Node) string {
+	if n.ID < 0 {
+		return "unknown"
+	}
+	return fmt.Sprintf("%d (%s:%d)", n.ID, n.Host, n.Port)
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// runCLI dispatches args with the output streams captured.
+func runCLI(t *testing.T, args ...string) (int, string, string) {
+	var out, errOut bytes.Buffer
+	oldOut, oldErr := stdout, stderr
+	stdout, stderr = &out, &errOut
+	t.Cleanup(func() { stdout, stderr = oldOut, oldErr })
+	code := dispatch("kafctl", commands(), args)
+	return code, out.String(), errOut.String()
+}
+
+func Test_GroupsSubcommands(t *testing.T) {
+
+	for _, name := range []string{"list", "describe", "lag", "reset-offsets", "delete"} {
+		code, _, errOut := runCLI(t, "groups", name, "-h")
+		assert.Equal(t, ExitOK, code, name)
+		assert.Contains(t, errOut, "Usage: kafctl groups "+name, name)
+		assert.NotContains(t, errOut, "unknown command", name)
+	}
+
+	code, out, _ := runCLI(t, "groups", "-h")
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, out, "lag")
+
+	code, _, errOut := runCLI(t, "groups", "lag", "-by", "cluster")
+	assert.Equal(t, ExitUsage, code)
+	assert.Contains(t, errOut, "unknown -by 'cluster'")
+
+	code, _, errOut = runCLI(t, "groups", "reset-offsets", "-g", "orders", "-to-earliest", "-to-latest")
+	assert.Equal(t, ExitUsage, code)
+	assert.Contains(t, errOut, "exactly one reset mode is required, got 2")
+
+	code, _, errOut = runCLI(t, "groups", "reset-offsets", "-to-earliest")
+	assert.Equal(t, ExitUsage, code)
+	assert.Contains(t, errOut, "-g is required")
+
+	code, _, errOut = runCLI(t, "groups", "delete", "-match", "^preview-", "orders")
+	assert.Equal(t, ExitUsage, code)
+	assert.Contains(t, errOut, "group names cannot be combined with -match or -empty-for")
+}
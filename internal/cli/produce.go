@@ -0,0 +1,310 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"kafctl/internal/config"
+	"kafctl/internal/services"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+func produceCommand() *command {
+	return &command{
+		name:    "produce",
+		summary: "Publish a message, or replay an export file, to a topic",
+		run:     produce,
+	}
+}
+
+func produce(args []string) int {
+	var conn connOptions
+	var topic, key, headersFile, message, partitioner, timestamp string
+	var headers headerFlag
+	var partition int
+	var replay replayFlags
+	var bulk bulkFlags
+	fs := newFlagSet("produce", "produce -t <topic> [-k key] [-H key=value...] [-headers-file file] [-P partition | -partitioner name] [-timestamp time] [-m message] [flags]\n"+
+		"       kafctl produce -t <topic> -input <file> [-input-format lines|json|csv] [-rate n] [-concurrency n] [-H ...] [-P partition | -partitioner name] [flags]\n"+
+		"       kafctl produce -from-file <export file> [-t topic] [-keep-partition] [-provenance] [flags]\n\n"+
+		"The message is read from stdin when -m is not given. Without -k the message has no\n"+
+		"key, -k '' sends an empty key. -partitioner picks the partition from the key:\n"+
+		"murmur2 like the Java client or consistent-random like librdkafka (the default).\n"+
+		"-H can be repeated and keys can repeat: key=value is a text value, key:base64=...\n"+
+		"and key:hex=... are binary values and key:null is a header without value.\n"+
+		"-headers-file reads a JSON array of {\"key\", \"value\", \"encoding\"} objects,\n"+
+		"its headers come before those of -H.\n"+
+		"-input publishes every message of a file: one value per line, a JSON array of\n"+
+		"{\"key\", \"headers\", \"value\"} objects or CSV with key, value and optional headers\n"+
+		"columns. -H, -P and -partitioner apply to every message. -rate caps messages per\n"+
+		"second and -concurrency the messages waiting for delivery; Ctrl-C stops reading.\n"+
+		"With -from-file the records of a kafctl export are published again, to their\n"+
+		"source topic unless -t is given.", &conn)
+	fs.StringVar(&topic, "topic", "", "Kafka topic to publish to (mandatory)")
+	fs.StringVar(&topic, "t", "", "Kafka topic to publish to (mandatory, shorthand)")
+	fs.StringVar(&key, "key", "", "Message key")
+	fs.StringVar(&key, "k", "", "Message key (shorthand)")
+	fs.Var(&headers, "header", "Message header as key=value, key:base64=..., key:hex=... or key:null (repeatable)")
+	fs.Var(&headers, "H", "Message header (repeatable, shorthand)")
+	fs.StringVar(&headersFile, "headers-file", "", "JSON file with the message headers")
+	fs.IntVar(&partition, "partition", -1, "Partition to publish to (default chosen by the partitioner)")
+	fs.IntVar(&partition, "P", -1, "Partition to publish to (shorthand)")
+	fs.StringVar(&partitioner, "partitioner", "", "Partitioner of the key: "+strings.Join(services.Partitioners, ", "))
+	fs.StringVar(&timestamp, "timestamp", "", "Message timestamp (RFC 3339, 'YYYY-MM-DD hh:mm:ss' UTC or Unix ms, default now)")
+	fs.StringVar(&message, "message", "", "Message payload")
+	fs.StringVar(&message, "m", "", "Message payload (shorthand)")
+	replay.register(fs)
+	bulk.register(fs)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	var given []string
+	fs.Visit(func(f *flag.Flag) { given = append(given, f.Name) })
+	keySet := slices.Contains(given, "key") || slices.Contains(given, "k")
+	replayOnly := givenFlags(given, "format", "keep-partition", "provenance")
+	bulkOnly := givenFlags(given, "input-format", "rate", "concurrency")
+
+	if replay.inputFile != "" {
+		if bulk.inputFile != "" {
+			return usageError(fs, "-from-file and -input cannot be combined")
+		}
+		if message != "" || keySet || len(headers) > 0 || headersFile != "" || partition >= 0 || partitioner != "" || timestamp != "" {
+			return usageError(fs, "-from-file cannot be combined with -m, -k, -H, -headers-file, -P, -partitioner or -timestamp")
+		}
+		if bulkOnly != "" {
+			return usageError(fs, "-from-file cannot be combined with %s", bulkOnly)
+		}
+		return produceFromFile(&conn, topic, &replay)
+	}
+	if replayOnly != "" {
+		return usageError(fs, "%s can only be used with -from-file", replayOnly)
+	}
+	if bulk.inputFile == "" && bulkOnly != "" {
+		return usageError(fs, "%s can only be used with -input", bulkOnly)
+	}
+
+	if code := initConfig(&conn, topic, "", false); code != ExitOK {
+		return code
+	}
+	if config.Topic == "" {
+		return usageError(fs, "topic is required")
+	}
+	if partition >= 0 && partitioner != "" {
+		return usageError(fs, "-P and -partitioner cannot be combined")
+	}
+	if partitioner != "" && !slices.Contains(services.Partitioners, partitioner) {
+		return usageError(fs, "unknown partitioner '%s', expected one of %s", partitioner, strings.Join(services.Partitioners, ", "))
+	}
+	var recordHeaders []services.RecordHeader
+	if headersFile != "" {
+		fileHeaders, err := services.ReadHeadersFile(headersFile)
+		if err != nil {
+			return usageError(fs, "%v", err)
+		}
+		recordHeaders = fileHeaders
+	}
+	recordHeaders = append(recordHeaders, headers...)
+	h, err := services.KafkaHeaders(recordHeaders)
+	if err != nil {
+		return usageError(fs, "%v", err)
+	}
+
+	if bulk.inputFile != "" {
+		if message != "" || keySet || timestamp != "" {
+			return usageError(fs, "-input cannot be combined with -m, -k or -timestamp")
+		}
+		if bulk.rate < 0 || bulk.concurrency < 0 {
+			return usageError(fs, "-rate and -concurrency must not be negative")
+		}
+		return produceBulk(services.BulkOptions{
+			InputFile:   bulk.inputFile,
+			Format:      bulk.format,
+			Topic:       config.Topic,
+			Partition:   int32(partition),
+			Partitioner: partitioner,
+			Headers:     h,
+			Limits:      services.ProduceLimits{Rate: bulk.rate, InFlight: bulk.concurrency},
+		})
+	}
+
+	spec := services.MessageSpec{Topic: config.Topic, Partition: int32(partition), Partitioner: partitioner, Headers: h}
+	if keySet {
+		spec.Key = []byte(key)
+	}
+	if timestamp != "" {
+		t, err := services.ParseWindowTime(timestamp)
+		if err != nil {
+			return usageError(fs, "invalid -timestamp: %v", err)
+		}
+		spec.Timestamp = t
+	}
+
+	payload := []byte(message)
+	if message == "" {
+		data, err := io.ReadAll(input())
+		if err != nil {
+			return runError("reading message from stdin", err)
+		}
+		payload = data
+	}
+	spec.Value = payload
+
+	res, err := services.ProduceMessage(spec)
+	if err != nil {
+		return runError("publishing message", err)
+	}
+
+	fmt.Fprintf(stdout, "Message published to topic '%s', partition %d at offset %d\n", res.Topic, res.Partition, res.Offset)
+	return ExitOK
+}
+
+// givenFlags lists the flags of names among the given ones, in the order of
+// given.
+func givenFlags(given []string, names ...string) string {
+	var flags []string
+	for _, name := range given {
+		if slices.Contains(names, name) {
+			flags = append(flags, "-"+name)
+		}
+	}
+	return strings.Join(flags, ", ")
+}
+
+// headerFlag collects the headers of a repeated -H, parsed when given.
+type headerFlag []services.RecordHeader
+
+func (f *headerFlag) String() string {
+	keys := make([]string, 0, len(*f))
+	for _, h := range *f {
+		keys = append(keys, h.Key)
+	}
+	return strings.Join(keys, ",")
+}
+
+func (f *headerFlag) Set(s string) error {
+	h, err := services.ParseHeaderFlag(s)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, h)
+	return nil
+}
+
+type replayFlags struct {
+	inputFile     string
+	format        string
+	keepPartition bool
+	provenance    bool
+}
+
+func (rf *replayFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&rf.inputFile, "from-file", "", "Re-publish the records of a kafctl export file")
+	fs.StringVar(&rf.format, "format", "", "Format of the -from-file input: "+strings.Join(services.Formats, ", ")+" (default: from the file extension)")
+	fs.BoolVar(&rf.keepPartition, "keep-partition", false, "Publish each record to the partition it was exported from")
+	fs.BoolVar(&rf.provenance, "provenance", false, "Add the source topic, partition and offset as headers")
+}
+
+// produceFromFile replays an export file. Unlike single messages it does not
+// fall back to the topic of the app config, records go back to their source
+// topic unless one is given.
+func produceFromFile(conn *connOptions, topic string, rf *replayFlags) int {
+	if code := initConfig(conn, "", "", false); code != ExitOK {
+		return code
+	}
+
+	summary, err := services.ReplayMessages(services.ReplayOptions{
+		InputFile:     rf.inputFile,
+		Format:        rf.format,
+		Topic:         topic,
+		KeepPartition: rf.keepPartition,
+		Provenance:    rf.provenance,
+	})
+	if summary != nil {
+		printProduceSummary(summary)
+	}
+	if err != nil {
+		return runError("replaying messages", err)
+	}
+	if summary.Failed > 0 {
+		return runError("replaying messages", fmt.Errorf("%d message(s) were not delivered: %v", summary.Failed, summary.FirstError))
+	}
+	return ExitOK
+}
+
+type bulkFlags struct {
+	inputFile   string
+	format      string
+	rate        float64
+	concurrency int
+}
+
+func (bf *bulkFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&bf.inputFile, "input", "", "Publish every message of a file")
+	fs.StringVar(&bf.format, "input-format", "", "Format of the -input file: "+strings.Join(services.BulkFormats, ", ")+" (default: json and csv by extension, else lines)")
+	fs.Float64Var(&bf.rate, "rate", 0, "Maximum messages per second of -input (default no limit)")
+	fs.IntVar(&bf.concurrency, "concurrency", 0, "Maximum messages of -input waiting for delivery (default no limit)")
+}
+
+// produceBulk publishes an input file until it ends or Ctrl-C and prints the
+// delivery report, also when it was stopped.
+func produceBulk(opts services.BulkOptions) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	summary, err := services.ProduceBulk(ctx, opts)
+	if summary != nil {
+		printProduceSummary(summary)
+	}
+	if err != nil {
+		return runError("producing messages", err)
+	}
+	if summary.Failed > 0 {
+		return runError("producing messages", fmt.Errorf("%d message(s) were not delivered: %v", summary.Failed, summary.FirstError))
+	}
+	return ExitOK
+}
+
+func printProduceSummary(s *services.ProduceSummary) {
+	rate := 0.0
+	if s.Elapsed > 0 {
+		rate = float64(s.Delivered) / s.Elapsed.Seconds()
+	}
+	fmt.Fprintf(stdout, "Read %d records from %s, delivered %d, failed %d in %s (%.0f msg/s)\n",
+		s.Read, s.InputFile, s.Delivered, s.Failed, s.Elapsed.Round(time.Millisecond), rate)
+	printDeliveredPartitions(s.Partitions)
+}
+
+// printDeliveredPartitions prints the messages delivered to every partition.
+func printDeliveredPartitions(partitions map[string]map[int32]int64) {
+	if len(partitions) == 0 {
+		return
+	}
+
+	fmt.Fprintln(stdout)
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TOPIC\tPARTITION\tDELIVERED")
+	topics := make([]string, 0, len(partitions))
+	for topic := range partitions {
+		topics = append(topics, topic)
+	}
+	slices.Sort(topics)
+	for _, topic := range topics {
+		ids := make([]int32, 0, len(partitions[topic]))
+		for p := range partitions[topic] {
+			ids = append(ids, p)
+		}
+		slices.Sort(ids)
+		for _, p := range ids {
+			fmt.Fprintf(tw, "%s\t%d\t%d\n", topic, p, partitions[topic][p])
+		}
+	}
+	tw.Flush()
+}
@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ProduceFlagCombinations(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"input format with replay", []string{"-from-file", "out.jsonl", "-input-format", "csv"}, "-from-file cannot be combined with -input-format"},
+		{"limits with replay", []string{"-from-file", "out.jsonl", "-rate", "10", "-concurrency", "5"}, "-from-file cannot be combined with -concurrency, -rate"},
+		{"replay flags with input", []string{"-input", "in.csv", "-format", "csv", "-provenance"}, "-format, -provenance can only be used with -from-file"},
+		{"replay flags with a message", []string{"-t", "orders", "-m", "x", "-keep-partition"}, "-keep-partition can only be used with -from-file"},
+		{"input flags with a message", []string{"-t", "orders", "-m", "x", "-rate", "10"}, "-rate can only be used with -input"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, errOut := runCLI(t, append([]string{"produce"}, tt.args...)...)
+			assert.Equal(t, ExitUsage, code)
+			assert.Contains(t, errOut, tt.want)
+		})
+	}
+}
@@ -0,0 +1,419 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"kafctl/internal/services"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+func topicsCommand() *command {
+	return &command{
+		name:    "topics",
+		summary: "List, describe, create, delete, resize and purge topics",
+		subcommands: []*command{
+			{name: "list", summary: "List all topics", run: topicsList},
+			{name: "describe", summary: "Show partitions, leaders and replicas of a topic", run: topicsDescribe},
+			{name: "create", summary: "Create a topic", run: topicsCreate},
+			{name: "delete", summary: "Delete a topic", run: topicsDelete},
+			{name: "add-partitions", summary: "Increase the partition count of a topic", run: topicsAddPartitions},
+			{name: "config", summary: "Show or change the configs of a topic", run: topicsConfig},
+			{name: "purge", summary: "Delete the records of a topic before an offset or time", run: topicsPurge},
+		},
+	}
+}
+
+func topicsList(args []string) int {
+	var conn connOptions
+	fs := newFlagSet("topics list", "topics list [flags]", &conn)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	return withAdmin(&conn, "", func(admin services.IKafAdmin) int {
+		topics, err := admin.GetAllTopics()
+		if err != nil {
+			return runError("listing topics", err)
+		}
+
+		names := make([]string, 0, len(topics))
+		for name := range topics {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "TOPIC\tPARTITIONS\tREPLICAS")
+		for _, name := range names {
+			partitions := topics[name].Partitions
+			replicas := 0
+			if len(partitions) > 0 {
+				replicas = len(partitions[0].Replicas)
+			}
+			fmt.Fprintf(tw, "%s\t%d\t%d\n", name, len(partitions), replicas)
+		}
+		tw.Flush()
+		return ExitOK
+	})
+}
+
+func topicsDescribe(args []string) int {
+	var conn connOptions
+	var topic string
+	fs := newFlagSet("topics describe", "topics describe -t <topic> [flags]", &conn)
+	fs.StringVar(&topic, "topic", "", "Topic to describe (mandatory)")
+	fs.StringVar(&topic, "t", "", "Topic to describe (mandatory, shorthand)")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if topic == "" {
+		return usageError(fs, "topic is required")
+	}
+
+	return withAdmin(&conn, topic, func(admin services.IKafAdmin) int {
+		res, err := admin.DescribeTopic(topic)
+		if err != nil {
+			return runError("describing topic", err)
+		}
+
+		for _, t := range res.TopicDescriptions {
+			if t.Error.Code() != 0 {
+				return runError("describing topic "+t.Name, t.Error)
+			}
+			fmt.Fprintf(stdout, "Topic: %s\nTopic ID: %s\nInternal: %t\n\n", t.Name, t.TopicID, t.IsInternal)
+
+			tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(tw, "PARTITION\tLEADER\tREPLICAS\tISR")
+			for _, p := range t.Partitions {
+				leader := -1
+				if p.Leader != nil {
+					leader = p.Leader.ID
+				}
+				fmt.Fprintf(tw, "%d\t%d\t%v\t%v\n", p.Partition, leader, nodeIDs(p.Replicas), nodeIDs(p.Isr))
+			}
+			tw.Flush()
+		}
+		return ExitOK
+	})
+}
+
+func topicsCreate(args []string) int {
+	var conn connOptions
+	var topic string
+	var partitions, replicas int
+	var presets stringList
+	var validateOnly bool
+	configs := topicConfigFlag{}
+	fs := newFlagSet("topics create", "topics create -t <topic> [-p partitions] [-r replicas] [-c name=value] [-preset name] [-validate-only] [flags]\n\n"+
+		"-topic-config and -preset can be repeated; explicit configs win over presets. Presets:\n"+
+		"  "+strings.Join(services.TopicPresetNames(), ", "), &conn)
+	fs.StringVar(&topic, "topic", "", "Topic to create (mandatory)")
+	fs.StringVar(&topic, "t", "", "Topic to create (mandatory, shorthand)")
+	fs.IntVar(&partitions, "partitions", 1, "Number of partitions")
+	fs.IntVar(&partitions, "p", 1, "Number of partitions (shorthand)")
+	fs.IntVar(&replicas, "replicas", 1, "Replication factor")
+	fs.IntVar(&replicas, "r", 1, "Replication factor (shorthand)")
+	fs.Var(&presets, "preset", "Apply a named set of configs (repeatable)")
+	fs.BoolVar(&validateOnly, "validate-only", false, "Only let the broker validate the request, nothing is created")
+	fs.Var(configs, "topic-config", "Topic config name=value (repeatable)")
+	fs.Var(configs, "c", "Topic config name=value (repeatable, shorthand)")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if topic == "" {
+		return usageError(fs, "topic is required")
+	}
+	if partitions < 1 || replicas < 1 {
+		return usageError(fs, "partitions and replicas must be at least 1")
+	}
+	config, err := services.TopicConfigFromPresets(presets, configs)
+	if err != nil {
+		return usageError(fs, "%v", err)
+	}
+
+	return withAdmin(&conn, topic, func(admin services.IKafAdmin) int {
+		spec := services.TopicSpec{Name: topic, Partitions: partitions, ReplicationFactor: replicas, Config: config, ValidateOnly: validateOnly}
+		if err := admin.CreateTopicWithConfig(spec); err != nil {
+			return runError("creating topic", err)
+		}
+		if validateOnly {
+			fmt.Fprintf(stdout, "Topic '%s' is valid, nothing was created\n", topic)
+		} else {
+			fmt.Fprintf(stdout, "Topic '%s' created successfully!\n", topic)
+		}
+		names := slices.Sorted(maps.Keys(config))
+		for _, name := range names {
+			fmt.Fprintf(stdout, "  %s=%s\n", name, config[name])
+		}
+		return ExitOK
+	})
+}
+
+// topicConfigFlag collects the name=value topic configs of topics create.
+type topicConfigFlag map[string]string
+
+func (f topicConfigFlag) String() string {
+	pairs := make([]string, 0, len(f))
+	for _, name := range slices.Sorted(maps.Keys(f)) {
+		pairs = append(pairs, name+"="+f[name])
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (f topicConfigFlag) Set(s string) error {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("invalid config '%s', expected name=value", s)
+	}
+	f[strings.TrimSpace(name)] = value
+	return nil
+}
+
+func topicsDelete(args []string) int {
+	var conn connOptions
+	var topic string
+	fs := newFlagSet("topics delete", "topics delete -t <topic> [flags]", &conn)
+	fs.StringVar(&topic, "topic", "", "Topic to delete (mandatory)")
+	fs.StringVar(&topic, "t", "", "Topic to delete (mandatory, shorthand)")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if topic == "" {
+		return usageError(fs, "topic is required")
+	}
+
+	return withAdmin(&conn, topic, func(admin services.IKafAdmin) int {
+		if err := admin.DeleteTopic(topic); err != nil {
+			return runError("deleting topic", err)
+		}
+		fmt.Fprintf(stdout, "Topic '%s' deleted successfully!\n", topic)
+		return ExitOK
+	})
+}
+
+func topicsAddPartitions(args []string) int {
+	var conn connOptions
+	var topic string
+	var partitions int
+	var validateOnly, yes bool
+	fs := newFlagSet("topics add-partitions", "topics add-partitions -t <topic> -p <count> [-validate-only] [-yes] [flags]\n\n"+
+		"Partitions can only be added. Keys hash to other partitions afterwards, so\n"+
+		"messages with the same key are no longer ordered across the change.", &conn)
+	fs.StringVar(&topic, "topic", "", "Topic to resize (mandatory)")
+	fs.StringVar(&topic, "t", "", "Topic to resize (mandatory, shorthand)")
+	fs.IntVar(&partitions, "partitions", 0, "New total number of partitions (mandatory)")
+	fs.IntVar(&partitions, "p", 0, "New total number of partitions (mandatory, shorthand)")
+	fs.BoolVar(&validateOnly, "validate-only", false, "Only let the broker validate the request, nothing is changed")
+	fs.BoolVar(&yes, "yes", false, "Do not ask for confirmation")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if topic == "" {
+		return usageError(fs, "topic is required")
+	}
+	if partitions < 1 {
+		return usageError(fs, "partitions must be at least 1")
+	}
+
+	return withAdmin(&conn, topic, func(admin services.IKafAdmin) int {
+		res, err := admin.DescribeTopic(topic)
+		if err != nil {
+			return runError("describing topic", err)
+		}
+		if len(res.TopicDescriptions) == 0 || res.TopicDescriptions[0].Error.Code() != 0 {
+			return runError("describing topic", fmt.Errorf("topic '%s' not found", topic))
+		}
+		current := len(res.TopicDescriptions[0].Partitions)
+		if partitions <= current {
+			return runError("adding partitions", fmt.Errorf("topic '%s' already has %d partitions, the new count must be higher", topic, current))
+		}
+
+		fmt.Fprintf(stderr, "Topic '%s': %d -> %d partitions\n", topic, current, partitions)
+		fmt.Fprintln(stderr, "Warning: keys will map to different partitions, ordering of messages with the same key changes.")
+		if !validateOnly && !yes && !confirm("Add the partitions?") {
+			fmt.Fprintln(stderr, "Aborted, nothing was changed")
+			return ExitError
+		}
+
+		if err := admin.CreatePartitions(topic, partitions, validateOnly); err != nil {
+			return runError("adding partitions", err)
+		}
+		if validateOnly {
+			fmt.Fprintf(stdout, "Topic '%s' can grow to %d partitions, nothing was changed\n", topic, partitions)
+		} else {
+			fmt.Fprintf(stdout, "Topic '%s' now has %d partitions\n", topic, partitions)
+		}
+		return ExitOK
+	})
+}
+
+func topicsPurge(args []string) int {
+	var conn connOptions
+	var topic, beforeTime string
+	var partition int
+	var beforeOffset int64
+	var all, dryRun, yes bool
+	fs := newFlagSet("topics purge", "topics purge -t <topic> [-P partition] <mode> [-dry-run] [-yes] [flags]\n\n"+
+		"Exactly one mode is required: -before-offset, -before-time or -all. Without -P\n"+
+		"every partition is purged. Records before the target are deleted for good and\n"+
+		"targets are kept between the earliest and latest offset of each partition.\n"+
+		"-dry-run prints how many messages each partition would lose.", &conn)
+	fs.StringVar(&topic, "topic", "", "Topic to purge (mandatory)")
+	fs.StringVar(&topic, "t", "", "Topic to purge (mandatory, shorthand)")
+	fs.IntVar(&partition, "partition", -1, "Only purge this partition (default all)")
+	fs.IntVar(&partition, "P", -1, "Only purge this partition (shorthand)")
+	fs.Int64Var(&beforeOffset, services.PurgeBeforeOffset, 0, "Delete the records before the offset N")
+	fs.StringVar(&beforeTime, services.PurgeBeforeTime, "", "Delete the records before a time (RFC 3339, 'YYYY-MM-DD hh:mm:ss' UTC or Unix ms)")
+	fs.BoolVar(&all, services.PurgeAll, false, "Delete every record")
+	fs.BoolVar(&dryRun, "dry-run", false, "Print the plan without deleting anything")
+	fs.BoolVar(&yes, "yes", false, "Do not ask for confirmation")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if topic == "" {
+		return usageError(fs, "topic is required")
+	}
+
+	var modes []string
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case services.PurgeBeforeOffset, services.PurgeBeforeTime, services.PurgeAll:
+			modes = append(modes, f.Name)
+		}
+	})
+	if len(modes) != 1 {
+		return usageError(fs, "exactly one purge mode is required, got %d", len(modes))
+	}
+	spec := services.PurgeSpec{Mode: modes[0], Partition: int32(partition), Offset: beforeOffset}
+	if spec.Mode == services.PurgeBeforeTime {
+		t, err := services.ParseWindowTime(beforeTime)
+		if err != nil {
+			return usageError(fs, "%v", err)
+		}
+		spec.Time = t
+	}
+
+	return withAdmin(&conn, topic, func(admin services.IKafAdmin) int {
+		plan, err := admin.PlanPurge(topic, spec)
+		if err != nil {
+			return runError("planning purge", err)
+		}
+
+		var total int64
+		tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "PARTITION\tEARLIEST\tPURGE UP TO\tLATEST\tMESSAGES")
+		for _, p := range plan {
+			fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%d\n", p.Partition, p.Earliest, p.Target, p.Latest, p.Count())
+			total += p.Count()
+		}
+		tw.Flush()
+		fmt.Fprintf(stderr, "%d messages of topic '%s' will be removed\n", total, topic)
+
+		if dryRun {
+			fmt.Fprintf(stderr, "Dry run, topic '%s' was not changed\n", topic)
+			return ExitOK
+		}
+		if total == 0 {
+			fmt.Fprintln(stderr, "Nothing to purge")
+			return ExitOK
+		}
+		if !yes && !confirm("Delete the records?") {
+			fmt.Fprintln(stderr, "Aborted, nothing was changed")
+			return ExitError
+		}
+		if err := admin.PurgeRecords(plan); err != nil {
+			return runError("purging records", err)
+		}
+		fmt.Fprintf(stderr, "Purged %d messages of topic '%s' %s\n", total, topic, spec)
+		return ExitOK
+	})
+}
+
+func topicsConfig(args []string) int {
+	var conn connOptions
+	var topic string
+	var overrides bool
+	var sets, deletes, appends stringList
+	fs := newFlagSet("topics config", "topics config -t <topic> [-overrides] [-set k=v] [-delete k] [-append k=v] [flags]\n\n"+
+		"Without changes every config is printed with its value and source. -set,\n"+
+		"-delete (back to the default) and -append (to list configs such as\n"+
+		"cleanup.policy) can be repeated and are applied in one request.", &conn)
+	fs.StringVar(&topic, "topic", "", "Topic of the configs (mandatory)")
+	fs.StringVar(&topic, "t", "", "Topic of the configs (mandatory, shorthand)")
+	fs.BoolVar(&overrides, "overrides", false, "Only print configs that are not defaults")
+	fs.Var(&sets, services.ConfigSet, "Set a config, name=value (repeatable)")
+	fs.Var(&deletes, services.ConfigDelete, "Delete a config override, name (repeatable)")
+	fs.Var(&appends, services.ConfigAppend, "Append to a list config, name=value (repeatable)")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if topic == "" {
+		return usageError(fs, "topic is required")
+	}
+
+	var changes []services.ConfigChange
+	for _, group := range []struct {
+		op     string
+		values stringList
+	}{{services.ConfigSet, sets}, {services.ConfigDelete, deletes}, {services.ConfigAppend, appends}} {
+		for _, v := range group.values {
+			change, err := services.ParseConfigChange(group.op, v)
+			if err != nil {
+				return usageError(fs, "%v", err)
+			}
+			changes = append(changes, change)
+		}
+	}
+
+	return withAdmin(&conn, topic, func(admin services.IKafAdmin) int {
+		if len(changes) > 0 {
+			if err := admin.AlterTopicConfig(topic, changes); err != nil {
+				return runError("altering topic config", err)
+			}
+			for _, c := range changes {
+				fmt.Fprintf(stderr, "%s\n", c)
+			}
+			fmt.Fprintf(stderr, "Updated %d configs of topic '%s'\n", len(changes), topic)
+			return ExitOK
+		}
+
+		configs, err := admin.DescribeTopicConfig(topic)
+		if err != nil {
+			return runError("describing topic config", err)
+		}
+		tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tVALUE\tSOURCE\tREAD-ONLY")
+		for _, c := range configs {
+			if overrides && c.Default {
+				continue
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%t\n", c.Name, formatConfigValue(c), c.Source, c.ReadOnly)
+		}
+		tw.Flush()
+		return ExitOK
+	})
+}
+
+func formatConfigValue(c services.ConfigValue) string {
+	switch {
+	case c.Sensitive:
+		return "(sensitive)"
+	case c.Value == "":
+		return "-"
+	}
+	return c.Value
+}
+
+func nodeIDs(nodes []kafka.Node) []int {
+	ids := make([]int, 0, len(nodes))
+	for _, n := range nodes {
+		ids = append(ids, n.ID)
+	}
+	return ids
+}
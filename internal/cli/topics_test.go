@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TopicConfigFlag(t *testing.T) {
+
+	configs := topicConfigFlag{}
+	fs := newFlagSet("topics create", "topics create", &connOptions{})
+	fs.Var(configs, "topic-config", "")
+	fs.Var(configs, "c", "")
+	assert.NoError(t, fs.Parse([]string{"-c", "cleanup.policy=compact", "-topic-config", " retention.ms =1000",
+		"-config", "ssl.json"}))
+	assert.Equal(t, topicConfigFlag{"cleanup.policy": "compact", "retention.ms": "1000"}, configs)
+	assert.Equal(t, "ssl.json", fs.Lookup("config").Value.String())
+	assert.Equal(t, "cleanup.policy=compact,retention.ms=1000", configs.String())
+
+	code, _, errOut := runCLI(t, "topics", "create", "-t", "orders", "-c", "cleanup.policy")
+	assert.Equal(t, ExitUsage, code)
+	assert.Contains(t, errOut, "invalid config 'cleanup.policy', expected name=value")
+}
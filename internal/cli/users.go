@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"fmt"
+	"kafctl/internal/services"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+func usersCommand() *command {
+	return &command{
+		name:    "users",
+		summary: "List, create, update and delete SCRAM users",
+		subcommands: []*command{
+			{name: "list", summary: "List SCRAM users and their mechanisms", run: usersList},
+			{name: "upsert", summary: "Create or change the password of a SCRAM user", run: usersUpsert},
+			{name: "delete", summary: "Delete the SCRAM credentials of a user", run: usersDelete},
+		},
+	}
+}
+
+func usersList(args []string) int {
+	var conn connOptions
+	var users stringList
+	fs := newFlagSet("users list", "users list [-u user] [flags]\n\n"+
+		"Without -u every user with SCRAM credentials is listed.", &conn)
+	fs.Var(&users, "user", "User to describe (repeatable)")
+	fs.Var(&users, "u", "User to describe (repeatable, shorthand)")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	return withAdmin(&conn, "", func(admin services.IKafAdmin) int {
+		descriptions, err := admin.DescribeUserScramCredentials(users)
+		if err != nil {
+			return runError("listing users", err)
+		}
+
+		tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "USER\tMECHANISM\tITERATIONS")
+		for _, d := range descriptions {
+			if d.Error.Code() != kafka.ErrNoError {
+				fmt.Fprintf(tw, "%s\t%s\t-\n", d.User, d.Error)
+				continue
+			}
+			for _, c := range d.ScramCredentialInfos {
+				fmt.Fprintf(tw, "%s\t%s\t%d\n", d.User, c.Mechanism, c.Iterations)
+			}
+		}
+		tw.Flush()
+		return ExitOK
+	})
+}
+
+func usersUpsert(args []string) int {
+	var conn connOptions
+	var user, mechanism string
+	var iterations int
+	fs := newFlagSet("users upsert", "users upsert -u <user> [-mechanism SCRAM-SHA-256|SCRAM-SHA-512] [-iterations n] [flags]\n\n"+
+		"The password is prompted for without echo, or read from the first line of stdin\n"+
+		"when it is not a terminal.", &conn)
+	fs.StringVar(&user, "user", "", "User to create or update (mandatory)")
+	fs.StringVar(&user, "u", "", "User to create or update (mandatory, shorthand)")
+	fs.StringVar(&mechanism, "mechanism", services.SaslScramSha512, "SCRAM mechanism: "+strings.Join(services.ScramMechanisms, ", "))
+	fs.IntVar(&iterations, "iterations", services.DefaultScramIterations, "Iterations used to hash the password")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if user == "" {
+		return usageError(fs, "user is required")
+	}
+
+	password, err := readPassword(fmt.Sprintf("Password for %s: ", user))
+	if err != nil {
+		return runError("reading password", err)
+	}
+	if password == "" {
+		return usageError(fs, "password must not be empty")
+	}
+
+	return withAdmin(&conn, "", func(admin services.IKafAdmin) int {
+		if err := admin.UpsertUserScramCredential(user, mechanism, iterations, []byte(password)); err != nil {
+			return runError("upserting user", err)
+		}
+		fmt.Fprintf(stderr, "Stored %s credential of user '%s'\n", strings.ToUpper(mechanism), user)
+		return ExitOK
+	})
+}
+
+func usersDelete(args []string) int {
+	var conn connOptions
+	var user, mechanism string
+	fs := newFlagSet("users delete", "users delete -u <user> [-mechanism SCRAM-SHA-256|SCRAM-SHA-512] [flags]\n\n"+
+		"Without -mechanism every SCRAM credential of the user is deleted.", &conn)
+	fs.StringVar(&user, "user", "", "User to delete (mandatory)")
+	fs.StringVar(&user, "u", "", "User to delete (mandatory, shorthand)")
+	fs.StringVar(&mechanism, "mechanism", "", "Only delete the credential of this mechanism")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if user == "" {
+		return usageError(fs, "user is required")
+	}
+
+	return withAdmin(&conn, "", func(admin services.IKafAdmin) int {
+		mechanisms := []string{mechanism}
+		if mechanism == "" {
+			descriptions, err := admin.DescribeUserScramCredentials([]string{user})
+			if err != nil {
+				return runError("describing user", err)
+			}
+			mechanisms = nil
+			for _, d := range descriptions {
+				if d.Error.Code() != kafka.ErrNoError {
+					return runError("describing user", d.Error)
+				}
+				for _, c := range d.ScramCredentialInfos {
+					mechanisms = append(mechanisms, c.Mechanism.String())
+				}
+			}
+		}
+
+		for _, m := range mechanisms {
+			if err := admin.DeleteUserScramCredential(user, m); err != nil {
+				return runError("deleting user", err)
+			}
+			fmt.Fprintf(stderr, "Deleted %s credential of user '%s'\n", strings.ToUpper(m), user)
+		}
+		return ExitOK
+	})
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"kafctl/internal/config"
+	"kafctl/internal/handlers"
+	"kafctl/internal/logger"
+	"kafctl/internal/services"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Time given to running requests when kafView is stopped
+const viewShutdownTimeout = 10 * time.Second
+
+func viewCommand() *command {
+	return &command{
+		name:    "view",
+		summary: "Run the kafView dashboard",
+		run:     view,
+	}
+}
+
+func view(args []string) int {
+	var conn connOptions
+	var addr string
+	fs := newFlagSet("view", "view [-addr host:port] [flags]", &conn)
+	fs.StringVar(&addr, "addr", "", "Address to serve kafView on (defaults to kafViewUrl from the app config)")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if code := initConfig(&conn, "", "", true); code != ExitOK {
+		return code
+	}
+	if addr != "" {
+		config.KafViewUrl = addr
+	}
+
+	app := handlers.Application{}
+	admin, err := services.NewKafAdmin()
+	if err != nil {
+		logger.Error("Error initializing kafka admin", "error", err)
+		return runError("initializing kafka admin", err)
+	}
+	defer admin.Close()
+
+	mux, err := app.Routes(admin)
+	if err != nil {
+		logger.Error("Error creating routes", "error", err)
+		return runError("creating routes", err)
+	}
+
+	// Stop on Ctrl-C or SIGTERM so running requests finish and the shared
+	// producer is flushed by Run. Requests derive their context from ctx, so
+	// tail streams and generator runs end when the signal arrives.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{
+		Addr:        config.KafViewUrl,
+		Handler:     mux,
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	errCh := make(chan error, 1)
+	go func() { errCh <- server.ListenAndServe() }()
+
+	logger.Info("Running kafView dashboard on: ", "url", "http://"+config.KafViewUrl)
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		logger.Info("Stopping kafView")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), viewShutdownTimeout)
+		defer cancel()
+		if err = server.Shutdown(shutdownCtx); errors.Is(err, context.DeadlineExceeded) {
+			logger.Warn("Requests still running after shutdown timeout, closing them", "timeout", viewShutdownTimeout)
+			server.Close()
+			err = nil
+		}
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("Error opening kafView", "error", err)
+		return runError("opening kafView", err)
+	}
+	return ExitOK
+}
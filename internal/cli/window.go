@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"kafctl/internal/services"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// windowFlags selects a bounded range of messages by partition, offset and
+// time, optionally filtered by an expression.
+type windowFlags struct {
+	partitions string
+	fromOffset int64
+	toOffset   int64
+	fromTime   string
+	toTime     string
+	limit      int
+	filter     string
+}
+
+func (wf *windowFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&wf.partitions, "partitions", "", "Comma separated partitions to read (default all)")
+	fs.StringVar(&wf.partitions, "P", "", "Comma separated partitions to read (shorthand)")
+	fs.Int64Var(&wf.fromOffset, "from-offset", services.OffsetUnset, "First offset to read, inclusive")
+	fs.Int64Var(&wf.toOffset, "to-offset", services.OffsetUnset, "Last offset to read, inclusive")
+	fs.StringVar(&wf.fromTime, "from-time", "", "Read messages at or after this time (RFC 3339, 'YYYY-MM-DD hh:mm:ss' UTC or Unix ms)")
+	fs.StringVar(&wf.toTime, "to-time", "", "Read messages before this time (RFC 3339, 'YYYY-MM-DD hh:mm:ss' UTC or Unix ms)")
+	fs.IntVar(&wf.limit, "limit", 0, "Maximum number of messages to read (default no limit)")
+	fs.StringVar(&wf.filter, "filter", "", "Only keep messages matching the expression, e.g. 'key == \"abc\" && $.status == \"FAILED\"'")
+}
+
+// isSet reports whether any window flag was given.
+func (wf *windowFlags) isSet() bool {
+	return wf.partitions != "" || wf.fromOffset != services.OffsetUnset || wf.toOffset != services.OffsetUnset ||
+		wf.fromTime != "" || wf.toTime != "" || wf.limit != 0 || wf.filter != ""
+}
+
+func (wf *windowFlags) window() (services.MessageWindow, error) {
+	window := services.NewMessageWindow()
+	window.FromOffset = wf.fromOffset
+	window.ToOffset = wf.toOffset
+	window.Limit = wf.limit
+
+	if wf.limit < 0 {
+		return window, fmt.Errorf("limit must not be negative")
+	}
+	if wf.fromOffset < services.OffsetUnset || wf.toOffset < services.OffsetUnset {
+		return window, fmt.Errorf("offsets must not be negative")
+	}
+	if wf.toOffset != services.OffsetUnset && wf.fromOffset > wf.toOffset {
+		return window, fmt.Errorf("from-offset %d is after to-offset %d", wf.fromOffset, wf.toOffset)
+	}
+
+	partitions, err := parsePartitions(wf.partitions)
+	if err != nil {
+		return window, err
+	}
+	window.Partitions = partitions
+
+	if wf.fromTime != "" {
+		if window.FromTime, err = services.ParseWindowTime(wf.fromTime); err != nil {
+			return window, err
+		}
+	}
+	if wf.toTime != "" {
+		if window.ToTime, err = services.ParseWindowTime(wf.toTime); err != nil {
+			return window, err
+		}
+	}
+	if !window.FromTime.IsZero() && !window.ToTime.IsZero() && !window.FromTime.Before(window.ToTime) {
+		return window, fmt.Errorf("from-time must be before to-time")
+	}
+	if wf.filter != "" {
+		if window.Filter, err = services.ParseFilter(wf.filter); err != nil {
+			return window, err
+		}
+	}
+	return window, nil
+}
+
+func parsePartitions(s string) ([]int32, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var partitions []int32
+	for _, part := range strings.Split(s, ",") {
+		p, err := strconv.ParseInt(strings.TrimSpace(part), 10, 32)
+		if err != nil || p < 0 {
+			return nil, fmt.Errorf("invalid partition %q", part)
+		}
+		partitions = append(partitions, int32(p))
+	}
+	return partitions, nil
+}
+
+func printPartitionRanges(w io.Writer, ranges []services.PartitionRange, countHeader string) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "PARTITION\tSTART\tEND\tSCANNED\t%s\n", countHeader)
+	for _, p := range ranges {
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%d\n", p.Partition, p.StartOffset, p.EndOffset, p.Scanned, p.Count)
+	}
+	tw.Flush()
+}
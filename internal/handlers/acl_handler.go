@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/json"
+	"html/template"
+	"kafctl/internal/logger"
+	"kafctl/internal/models"
+	"kafctl/internal/services"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// Event sent with HX-Trigger after ACLs were created or deleted, reloading
+// the list on the page.
+const aclsChangedEvent = "aclsChanged"
+
+var aclsFuncMap = template.FuncMap{
+	"countPartitions":    countPartitions,
+	"countReplicas":      countReplicas,
+	"countIsrs":          countIsrs,
+	"aclResourceTypes":   func() []string { return services.ACLResourceTypes },
+	"aclPatternTypes":    func() []string { return services.ACLPatternTypes },
+	"aclOperations":      func() []string { return services.ACLOperations },
+	"aclPermissionTypes": func() []string { return services.ACLPermissionTypes },
+	"formatACL":          services.FormatACL,
+	"aclVals":            aclVals,
+}
+
+// aclsHandler renders the ACLs page. A POST creates one binding per selected
+// operation from the form.
+func (kah *KafAdminHandlers) aclsHandler(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodPost {
+		renderACLsTemplate(w, "acls", models.ACLs{})
+		return
+	}
+
+	data := models.ACLs{}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+	spec := aclSpecFromRequest(r)
+	var acls []services.ACLSpec
+	for _, op := range r.Form["operation"] {
+		s := spec
+		s.Operation = op
+		acls = append(acls, s)
+	}
+	if len(acls) == 0 {
+		acls = append(acls, spec)
+	}
+
+	var err error
+	for _, s := range acls {
+		acl, e := s.Binding()
+		if e != nil {
+			err = e
+			break
+		}
+		data.ACLs = append(data.ACLs, acl)
+	}
+	if err == nil {
+		err = kah.kafAdmin.CreateACLs(data.ACLs)
+	}
+	if err != nil {
+		logger.Error("Error creating ACLs", "error", err)
+		data.Error = err.Error()
+		data.ACLs = nil
+	} else {
+		data.Message = "Created " + strconv.Itoa(len(data.ACLs)) + " ACL bindings"
+		w.Header().Set("HX-Trigger", aclsChangedEvent)
+	}
+	renderACLsTemplate(w, "aclResult", data)
+}
+
+// aclListHandler renders the bindings matching the filter in the query.
+func (kah *KafAdminHandlers) aclListHandler(w http.ResponseWriter, r *http.Request) {
+
+	data := models.ACLs{Filter: aclSpecFromRequest(r)}
+	filter, err := data.Filter.Filter()
+	if err == nil {
+		data.ACLs, err = kah.kafAdmin.DescribeACLs(filter)
+	}
+	if err != nil {
+		logger.Error("Error listing ACLs", "error", err)
+		data.Error = err.Error()
+	}
+	renderACLsTemplate(w, "aclList", data)
+}
+
+// deleteACLHandler deletes exactly the binding posted by a row of the list.
+func (kah *KafAdminHandlers) deleteACLHandler(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	data := models.ACLs{}
+	acl, err := aclSpecFromRequest(r).Binding()
+	if err == nil {
+		data.ACLs, err = kah.kafAdmin.DeleteACLs(acl)
+	}
+	if err != nil {
+		logger.Error("Error deleting ACL", "error", err)
+		data.Error = err.Error()
+	} else {
+		data.Message = "Deleted " + strconv.Itoa(len(data.ACLs)) + " ACL bindings"
+		w.Header().Set("HX-Trigger", aclsChangedEvent)
+	}
+	renderACLsTemplate(w, "aclResult", data)
+}
+
+func aclSpecFromRequest(r *http.Request) services.ACLSpec {
+	return services.ACLSpec{
+		ResourceType: formOrQuery(r, "resourceType"),
+		Name:         formOrQuery(r, "resource"),
+		PatternType:  formOrQuery(r, "pattern"),
+		Principal:    formOrQuery(r, "principal"),
+		Host:         formOrQuery(r, "host"),
+		Operation:    formOrQuery(r, "operation"),
+		Permission:   formOrQuery(r, "permission"),
+	}
+}
+
+// aclVals encodes a binding as the hx-vals of its delete button.
+func aclVals(acl kafka.ACLBinding) string {
+	vals, _ := json.Marshal(map[string]string{
+		"resourceType": acl.Type.String(),
+		"resource":     acl.Name,
+		"pattern":      acl.ResourcePatternType.String(),
+		"principal":    acl.Principal,
+		"host":         acl.Host,
+		"operation":    acl.Operation.String(),
+		"permission":   acl.PermissionType.String(),
+	})
+	return string(vals)
+}
+
+func renderACLsTemplate(w http.ResponseWriter, name string, data any) {
+	files := []string{BASE_TEMPL_PATH, ACLS_TEMPL_PATH}
+	tmpl := template.Must(template.New(name).Funcs(aclsFuncMap).ParseFiles(files...))
+
+	err := tmpl.ExecuteTemplate(w, name, data)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal server error", 500)
+		return
+	}
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"kafctl/internal/logger"
+	"kafctl/internal/models"
+	"kafctl/internal/services"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+var brokersFuncMap = template.FuncMap{
+	"countPartitions": countPartitions,
+	"countReplicas":   countReplicas,
+	"countIsrs":       countIsrs,
+}
+
+// brokersHandler renders the cluster with its brokers and the broker configs
+// that differ between them, or every config with all=on.
+func (kah *KafAdminHandlers) brokersHandler(w http.ResponseWriter, r *http.Request) {
+
+	data := models.ClusterDetails{Controller: -1, All: r.URL.Query().Get("all") == "on"}
+	cluster, err := kah.kafAdmin.DescribeCluster()
+	if err == nil {
+		data.Brokers = cluster.Nodes
+		data.ClusterID, data.Controller = clusterIDAndController(cluster)
+
+		ids := make([]int, 0, len(cluster.Nodes))
+		for _, n := range cluster.Nodes {
+			ids = append(ids, n.ID)
+		}
+		var configs map[int][]services.ConfigValue
+		configs, err = services.DescribeBrokerConfigs(kah.kafAdmin, ids)
+		if err == nil {
+			data.Diffs = services.DiffBrokerConfigs(ids, configs, data.All)
+		}
+	}
+	if err != nil {
+		logger.Error("Error describing brokers", "error", err)
+		data.Error = err.Error()
+	}
+
+	renderBrokersTemplate(w, "clusterBrokers", data)
+}
+
+// brokerHandler renders one broker with every config and its source.
+func (kah *KafAdminHandlers) brokerHandler(w http.ResponseWriter, r *http.Request) {
+
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, "Missing or invalid broker id", http.StatusBadRequest)
+		return
+	}
+
+	data := models.BrokerDetails{Broker: kafka.Node{ID: id}, Overrides: r.URL.Query().Get("overrides") == "on"}
+	cluster, err := kah.kafAdmin.DescribeCluster()
+	if err == nil {
+		var controller int
+		data.ClusterID, controller = clusterIDAndController(cluster)
+		data.Controller = controller == id
+		err = fmt.Errorf("broker %d is not part of the cluster", id)
+		for _, n := range cluster.Nodes {
+			if n.ID == id {
+				data.Broker, err = n, nil
+			}
+		}
+	}
+	if err == nil {
+		var configs []services.ConfigValue
+		configs, err = kah.kafAdmin.DescribeBrokerConfig(id)
+		for _, c := range configs {
+			if !data.Overrides || !c.Default {
+				data.Configs = append(data.Configs, c)
+			}
+		}
+	}
+	if err != nil {
+		logger.Error("Error describing broker", "broker", id, "error", err)
+		data.Error = err.Error()
+	}
+
+	renderBrokersTemplate(w, "brokerDetails", data)
+}
+
+func clusterIDAndController(cluster kafka.DescribeClusterResult) (string, int) {
+	clusterID, controller := "unknown", -1
+	if cluster.ClusterID != nil {
+		clusterID = *cluster.ClusterID
+	}
+	if cluster.Controller != nil {
+		controller = cluster.Controller.ID
+	}
+	return clusterID, controller
+}
+
+func renderBrokersTemplate(w http.ResponseWriter, name string, data any) {
+	files := []string{BASE_TEMPL_PATH, BROKERS_TEMPL_PATH}
+	tmpl := template.Must(template.New(name).Funcs(brokersFuncMap).ParseFiles(files...))
+
+	err := tmpl.ExecuteTemplate(w, name, data)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal server error", 500)
+		return
+	}
+}
@@ -1,21 +1,38 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"html/template"
+	"io"
 	"kafctl/internal/logger"
 	"kafctl/internal/services"
 	"log"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 )
 
+// Messages scanned per partition when a filter is given without a window
+const filterScanDepth = 10000
+
+const (
+	// Messages buffered per tail client before polling is held up
+	tailBufferSize = 256
+	// Interval of keep-alive comments on idle tail streams
+	tailHeartbeatInterval = 15 * time.Second
+)
+
 type IKafConsumerHandlers interface {
 	ViewTopic(w http.ResponseWriter, r *http.Request)
 	ViewMessages(w http.ResponseWriter, r *http.Request)
+	TailView(w http.ResponseWriter, r *http.Request)
+	TailMessages(w http.ResponseWriter, r *http.Request)
 }
 
 type KafConsumerHandlers struct {
@@ -130,7 +147,24 @@ func (kch *KafConsumerHandlers) ViewMessages(w http.ResponseWriter, r *http.Requ
 		}
 	}
 
-	slog.Info("Getting messages", "topic", topicName, "partition", selectedPartition, "count", countPerPartition)
+	// Optional offset/time window and filter - check both form and query params
+	window, err := windowFromRequest(r)
+	if err != nil {
+		fmt.Fprintf(w, "Error viewing messages: %v", err)
+		return
+	}
+	if expr := strings.TrimSpace(formOrQuery(r, "filter")); expr != "" {
+		if window.Filter, err = services.ParseFilter(expr); err != nil {
+			fmt.Fprintf(w, "Error viewing messages: %v", err)
+			return
+		}
+		if !window.IsBounded() {
+			// Without bounds scan the tail of every partition only
+			window.Last = filterScanDepth
+		}
+	}
+
+	slog.Info("Getting messages", "topic", topicName, "partition", selectedPartition, "count", countPerPartition, "window", window)
 
 	consumer, err := services.NewConsumer()
 	if err != nil {
@@ -140,15 +174,32 @@ func (kch *KafConsumerHandlers) ViewMessages(w http.ResponseWriter, r *http.Requ
 	}
 	defer consumer.Close()
 
-	// Get messages from all partitions (or filtered by partition)
-	msg, err := consumer.GetLatestRecords(topicName, countPerPartition)
-	if err != nil {
-		fmt.Fprintf(w, "Error viewing messages: %v", err)
-		return
+	var msg []*kafka.Message
+	var windowResult *services.WindowResult
+	if window.IsBounded() {
+		// Read exactly the requested window, showing the last count messages of
+		// each partition
+		if selectedPartition != nil {
+			window.Partitions = []int32{*selectedPartition}
+		}
+		window.LastPerPartition = countPerPartition
+		windowResult, err = consumer.ConsumeWindow(topicName, window)
+		if err != nil {
+			fmt.Fprintf(w, "Error viewing messages: %v", err)
+			return
+		}
+		msg = windowResult.Messages
+	} else {
+		// Get messages from all partitions (or filtered by partition)
+		msg, err = consumer.GetLatestRecords(topicName, countPerPartition)
+		if err != nil {
+			fmt.Fprintf(w, "Error viewing messages: %v", err)
+			return
+		}
 	}
 
 	// Filter by partition if specified
-	if selectedPartition != nil {
+	if selectedPartition != nil && windowResult == nil {
 		filtered := make([]*kafka.Message, 0)
 		for _, m := range msg {
 			if m != nil && m.TopicPartition.Partition == *selectedPartition {
@@ -164,6 +215,7 @@ func (kch *KafConsumerHandlers) ViewMessages(w http.ResponseWriter, r *http.Requ
 	dataTemplate := map[string]any{
 		"Message":   msg,
 		"TopicName": topicName,
+		"Window":    windowResult,
 	}
 
 	funcMap := template.FuncMap{
@@ -184,3 +236,218 @@ func (kch *KafConsumerHandlers) ViewMessages(w http.ResponseWriter, r *http.Requ
 	}
 	logger.Info("Rendered messages template for viewMessages")
 }
+
+// formOrQuery returns a form value, falling back to the query parameter.
+func formOrQuery(r *http.Request, name string) string {
+	if v := r.FormValue(name); v != "" {
+		return v
+	}
+	return r.URL.Query().Get(name)
+}
+
+// windowFromRequest reads the optional fromOffset, toOffset, fromTime and
+// toTime parameters. Times from datetime-local inputs are taken as UTC.
+func windowFromRequest(r *http.Request) (services.MessageWindow, error) {
+	window := services.NewMessageWindow()
+
+	for name, target := range map[string]*int64{"fromOffset": &window.FromOffset, "toOffset": &window.ToOffset} {
+		if v := formOrQuery(r, name); v != "" {
+			parsed, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || parsed < 0 {
+				return window, fmt.Errorf("invalid %s %q", name, v)
+			}
+			*target = parsed
+		}
+	}
+
+	for name, target := range map[string]*time.Time{"fromTime": &window.FromTime, "toTime": &window.ToTime} {
+		if v := formOrQuery(r, name); v != "" {
+			parsed, err := services.ParseWindowTime(v)
+			if err != nil {
+				return window, err
+			}
+			*target = parsed
+		}
+	}
+	return window, nil
+}
+
+func (kch *KafConsumerHandlers) TailView(w http.ResponseWriter, r *http.Request) {
+
+	topicName := r.URL.Query().Get("topicname")
+	if topicName == "" {
+		http.Error(w, "Missing topic name", http.StatusBadRequest)
+		return
+	}
+
+	consumer, err := services.NewConsumer()
+	if err != nil {
+		logger.Error("Error creating consumer", "error", err)
+		http.Error(w, "Error creating consumer", http.StatusInternalServerError)
+		return
+	}
+	defer consumer.Close()
+
+	partitions, err := consumer.GetMessagesInfo(topicName)
+	if err != nil {
+		fmt.Fprintf(w, "Error viewing topic: %v", err)
+		return
+	}
+
+	dataTemplate := map[string]any{
+		"Partitions": partitions,
+		"TopicName":  topicName,
+	}
+
+	tmpl := template.Must(template.New("topicTail").ParseFiles(TAIL_TEMPL_PATH))
+	err = tmpl.ExecuteTemplate(w, "topicTail", dataTemplate)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal server error", 500)
+		return
+	}
+}
+
+// TailMessages streams new messages of a topic as Server-Sent Events until
+// the client disconnects. Each message is sent as a "message" event holding
+// the same JSON record as an export, failures as a "tail-error" event. A
+// slow client holds up polling through a bounded buffer instead of making
+// the server queue messages.
+func (kch *KafConsumerHandlers) TailMessages(w http.ResponseWriter, r *http.Request) {
+
+	topicName := r.URL.Query().Get("topicname")
+	if topicName == "" {
+		http.Error(w, "Missing topic name", http.StatusBadRequest)
+		return
+	}
+
+	var opts services.TailOptions
+	if p := r.URL.Query().Get("partition"); p != "" && p != "all" {
+		partition, err := strconv.ParseInt(p, 10, 32)
+		if err != nil || partition < 0 {
+			http.Error(w, fmt.Sprintf("Invalid partition %q", p), http.StatusBadRequest)
+			return
+		}
+		opts.Partitions = []int32{int32(partition)}
+	}
+	if last := r.URL.Query().Get("last"); last != "" {
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			http.Error(w, fmt.Sprintf("Invalid last %q", last), http.StatusBadRequest)
+			return
+		}
+		opts.Last = n
+	}
+	if expr := strings.TrimSpace(r.URL.Query().Get("filter")); expr != "" {
+		filter, err := services.ParseFilter(expr)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		opts.Filter = filter
+	}
+
+	rc := http.NewResponseController(w)
+	consumer, err := services.NewConsumer()
+	if err != nil {
+		logger.Error("Error creating consumer", "error", err)
+		http.Error(w, "Error creating consumer", http.StatusInternalServerError)
+		return
+	}
+	defer consumer.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		logger.Error("Streaming is not supported", "error", err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	messages := make(chan *kafka.Message, tailBufferSize)
+	tailErr := make(chan error, 1)
+	go func() {
+		tailErr <- consumer.Tail(ctx, topicName, opts, func(msg *kafka.Message) error {
+			select {
+			case messages <- msg:
+			case <-ctx.Done():
+			}
+			return nil
+		})
+	}()
+	// The consumer is closed only after Tail has returned.
+	defer func() {
+		cancel()
+		<-tailErr
+	}()
+
+	logger.Info("Tail client connected", "topic", topicName, "partitions", opts.Partitions, "filter", opts.Filter)
+	heartbeat := time.NewTicker(tailHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Tail client disconnected", "topic", topicName)
+			return
+
+		case err := <-tailErr:
+			// Put it back for the deferred wait
+			tailErr <- err
+			if err != nil {
+				logger.Error("Error tailing topic", "topic", topicName, "error", err)
+				writeSSE(w, "tail-error", "", err.Error())
+				rc.Flush()
+			}
+			return
+
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil || rc.Flush() != nil {
+				return
+			}
+
+		case msg := <-messages:
+			// Send what is buffered in one flush
+			for more := true; more; {
+				if err := writeSSEMessage(w, msg); err != nil {
+					return
+				}
+				select {
+				case msg = <-messages:
+				default:
+					more = false
+				}
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func writeSSEMessage(w http.ResponseWriter, msg *kafka.Message) error {
+	data, err := json.Marshal(services.NewMessageRecord(msg))
+	if err != nil {
+		return err
+	}
+	id := fmt.Sprintf("%d:%d", msg.TopicPartition.Partition, msg.TopicPartition.Offset)
+	return writeSSE(w, "message", id, string(data))
+}
+
+// writeSSE writes a single event, splitting multi-line data over data fields.
+func writeSSE(w http.ResponseWriter, event, id, data string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "event: %s\n", event)
+	if id != "" {
+		fmt.Fprintf(&b, "id: %s\n", id)
+	}
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
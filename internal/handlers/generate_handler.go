@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"kafctl/internal/logger"
+	"kafctl/internal/models"
+	"kafctl/internal/services"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// Messages rendered by a generator preview
+const generatePreviewCount = 5
+
+var generateFuncMap = template.FuncMap{
+	"countPartitions": countPartitions,
+	"countReplicas":   countReplicas,
+	"countIsrs":       countIsrs,
+	"inc":             incrementer,
+}
+
+func renderGenerateTemplate(w http.ResponseWriter, name string, data any) {
+	files := []string{BASE_TEMPL_PATH, GENERATE_TEMPL_PATH}
+	tmpl := template.Must(template.New(name).Funcs(generateFuncMap).ParseFiles(files...))
+
+	err := tmpl.ExecuteTemplate(w, name, data)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal server error", 500)
+		return
+	}
+}
+
+// generateHandler renders the generator page with the topics to publish to.
+func (kah *KafAdminHandlers) generateHandler(w http.ResponseWriter, r *http.Request) {
+
+	data := models.Generate{}
+	topics, err := kah.kafAdmin.GetAllTopics()
+	if err != nil {
+		logger.Error("Error getting topics", "error", err)
+		data.Error = err.Error()
+	}
+	for name := range topics {
+		data.Topics = append(data.Topics, name)
+	}
+	slices.Sort(data.Topics)
+	renderGenerateTemplate(w, "generate", data)
+}
+
+// generatePreviewHandler renders the first messages of the templates without
+// publishing them.
+func generatePreviewHandler(w http.ResponseWriter, r *http.Request) {
+
+	data := models.Generate{}
+	opts, err := generateOptionsFromRequest(r)
+	if err == nil {
+		data.Preview, err = previewMessages(opts)
+	}
+	if err != nil {
+		data.Error = err.Error()
+	}
+	renderGenerateTemplate(w, "generatePreview", data)
+}
+
+func previewMessages(opts services.GenerateOptions) ([]services.GeneratedMessage, error) {
+	gen, err := services.NewGenerator(opts.KeyTemplate, opts.ValueTemplate, opts.Seed)
+	if err != nil {
+		return nil, err
+	}
+	preview := make([]services.GeneratedMessage, 0, generatePreviewCount)
+	for i := 1; i <= generatePreviewCount; i++ {
+		msg, err := gen.Next()
+		if err != nil {
+			return preview, fmt.Errorf("message %d: %w", i, err)
+		}
+		preview = append(preview, msg)
+	}
+	return preview, nil
+}
+
+// generateResult is the final event of a generator run.
+type generateResult struct {
+	Sent       int64           `json:"sent"`
+	Delivered  int64           `json:"delivered"`
+	Failed     int64           `json:"failed"`
+	Elapsed    time.Duration   `json:"elapsed"`
+	Rate       float64         `json:"rate"`
+	Partitions map[int32]int64 `json:"partitions"`
+	Error      string          `json:"error,omitempty"`
+}
+
+// generateStreamHandler publishes generated messages and streams the
+// progress as Server-Sent Events: a "progress" event every second, one
+// "done" event with the delivery report when the run has ended and a
+// "generate-error" event for invalid options. The run stops when the client
+// disconnects.
+func generateStreamHandler(w http.ResponseWriter, r *http.Request) {
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		logger.Error("Streaming is not supported", "error", err)
+		return
+	}
+
+	opts, err := generateOptionsFromRequest(r)
+	if err == nil {
+		_, err = services.NewGenerator(opts.KeyTemplate, opts.ValueTemplate, opts.Seed)
+	}
+	if err != nil {
+		writeSSE(w, "generate-error", "", err.Error())
+		rc.Flush()
+		return
+	}
+
+	// Progress is reported from another goroutine while GenerateMessages
+	// runs, nothing else writes until it has returned.
+	opts.Progress = func(p services.GenerateProgress) {
+		data, err := json.Marshal(p)
+		if err == nil && writeSSE(w, "progress", "", string(data)) == nil {
+			rc.Flush()
+		}
+	}
+
+	logger.Info("Generator client connected", "topic", opts.Topic, "count", opts.Count, "duration", opts.Duration, "rate", opts.Limits.Rate)
+	summary, err := services.GenerateMessages(r.Context(), opts)
+
+	result := generateResult{}
+	if summary != nil {
+		result.Sent = summary.Delivered + summary.Failed
+		result.Delivered = summary.Delivered
+		result.Failed = summary.Failed
+		result.Elapsed = summary.Elapsed
+		if summary.Elapsed > 0 {
+			result.Rate = float64(summary.Delivered) / summary.Elapsed.Seconds()
+		}
+		result.Partitions = summary.Partitions[opts.Topic]
+		if err == nil && summary.FirstError != nil {
+			result.Error = fmt.Sprintf("%d message(s) were not delivered: %v", summary.Failed, summary.FirstError)
+		}
+	}
+	if err != nil {
+		logger.Error("Error generating messages", "topic", opts.Topic, "error", err)
+		result.Error = err.Error()
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	writeSSE(w, "done", "", string(data))
+	rc.Flush()
+}
+
+// generateOptionsFromRequest builds a generator run of the form. Empty
+// numbers mean no limit, the duration is in seconds and an empty partition
+// leaves the choice to the partitioner.
+func generateOptionsFromRequest(r *http.Request) (services.GenerateOptions, error) {
+	opts := services.GenerateOptions{
+		Topic:         formOrQuery(r, "topicName"),
+		KeyTemplate:   formOrQuery(r, "keyTemplate"),
+		ValueTemplate: formOrQuery(r, "valueTemplate"),
+		Partition:     kafka.PartitionAny,
+		Partitioner:   formOrQuery(r, "partitioner"),
+	}
+
+	var err error
+	if v := formOrQuery(r, "count"); v != "" {
+		if opts.Count, err = strconv.ParseInt(v, 10, 64); err != nil || opts.Count < 0 {
+			return opts, fmt.Errorf("invalid count '%s'", v)
+		}
+	}
+	if v := formOrQuery(r, "duration"); v != "" {
+		seconds, err := strconv.ParseFloat(v, 64)
+		if err != nil || seconds < 0 {
+			return opts, fmt.Errorf("invalid duration '%s'", v)
+		}
+		opts.Duration = time.Duration(seconds * float64(time.Second))
+	}
+	if v := formOrQuery(r, "rate"); v != "" {
+		if opts.Limits.Rate, err = strconv.ParseFloat(v, 64); err != nil || opts.Limits.Rate < 0 {
+			return opts, fmt.Errorf("invalid rate '%s'", v)
+		}
+	}
+	if v := formOrQuery(r, "concurrency"); v != "" {
+		if opts.Limits.InFlight, err = strconv.Atoi(v); err != nil || opts.Limits.InFlight < 0 {
+			return opts, fmt.Errorf("invalid concurrency '%s'", v)
+		}
+	}
+	if v := formOrQuery(r, "seed"); v != "" {
+		if opts.Seed, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return opts, fmt.Errorf("invalid seed '%s'", v)
+		}
+	}
+	if v := formOrQuery(r, "partition"); v != "" {
+		p, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || p < 0 {
+			return opts, fmt.Errorf("invalid partition '%s'", v)
+		}
+		if opts.Partitioner != "" {
+			return opts, fmt.Errorf("a partition and a partitioner cannot be combined")
+		}
+		opts.Partition = int32(p)
+	}
+	return opts, nil
+}
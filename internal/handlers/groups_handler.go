@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"kafctl/internal/logger"
+	"kafctl/internal/models"
+	"kafctl/internal/services"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+var groupsFuncMap = template.FuncMap{
+	"countPartitions":  countPartitions,
+	"countReplicas":    countReplicas,
+	"countIsrs":        countIsrs,
+	"formatAssignment": services.FormatAssignment,
+	"countAssigned":    countAssigned,
+	"resetModes":       func() []string { return resetModes },
+}
+
+func (kah *KafAdminHandlers) consumerGroupsHandler(w http.ResponseWriter, r *http.Request) {
+
+	data := models.ConsumerGroups{}
+	listings, err := kah.kafAdmin.ListConsumerGroups()
+	if err != nil {
+		logger.Error("Error listing consumer groups", "error", err)
+		data.Error = err.Error()
+	}
+
+	groupIDs := make([]string, 0, len(listings))
+	for _, g := range listings {
+		groupIDs = append(groupIDs, g.GroupID)
+	}
+	if len(groupIDs) > 0 {
+		data.Groups, err = kah.kafAdmin.DescribeConsumerGroups(groupIDs)
+		if err != nil {
+			logger.Error("Error describing consumer groups", "error", err)
+			data.Error = err.Error()
+		}
+	}
+
+	renderGroupsTemplate(w, "consumerGroups", data)
+}
+
+func (kah *KafAdminHandlers) consumerGroupHandler(w http.ResponseWriter, r *http.Request) {
+
+	groupID := r.URL.Query().Get("name")
+	if groupID == "" {
+		http.Error(w, "Missing group name", http.StatusBadRequest)
+		return
+	}
+
+	data := models.ConsumerGroupDetails{Group: kafka.ConsumerGroupDescription{GroupID: groupID}}
+	groups, err := kah.kafAdmin.DescribeConsumerGroups([]string{groupID})
+	switch {
+	case err != nil:
+		data.Error = err.Error()
+	case len(groups) == 0:
+		data.Error = "Consumer group not found"
+	case groups[0].Error.Code() != kafka.ErrNoError:
+		data.Error = groups[0].Error.Error()
+	default:
+		data.Group = groups[0]
+		data.Offsets, err = kah.kafAdmin.ListConsumerGroupOffsets(groupID)
+		if err != nil {
+			data.Error = err.Error()
+		}
+	}
+	if data.Error != "" {
+		logger.Error("Error describing consumer group", "group", groupID, "error", data.Error)
+	}
+
+	renderGroupsTemplate(w, "consumerGroupDetails", data)
+}
+
+// topicLagHandler renders the lag of every group consuming a topic as a
+// fragment of the topic details page.
+func (kah *KafAdminHandlers) topicLagHandler(w http.ResponseWriter, r *http.Request) {
+
+	topicName := r.URL.Query().Get("name")
+	if topicName == "" {
+		http.Error(w, "Missing topic name", http.StatusBadRequest)
+		return
+	}
+
+	data := models.TopicLag{Topic: topicName, SortBy: r.URL.Query().Get("sort"), SortBys: services.LagSortKeys}
+	if !slices.Contains(services.LagSortKeys, data.SortBy) {
+		data.SortBy = services.LagSortLag
+	}
+	if v := r.URL.Query().Get("minLag"); v != "" {
+		minLag, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || minLag < 0 {
+			http.Error(w, "Invalid minimum lag", http.StatusBadRequest)
+			return
+		}
+		data.MinLag = minLag
+	}
+
+	consumer, err := services.NewConsumer()
+	if err != nil {
+		logger.Error("Error creating consumer", "error", err)
+		http.Error(w, "Error creating consumer", http.StatusInternalServerError)
+		return
+	}
+	defer consumer.Close()
+
+	lags, err := services.NewLagService(kah.kafAdmin, consumer).TopicLag(topicName)
+	if err != nil {
+		logger.Error("Error computing topic lag", "topic", topicName, "error", err)
+		data.Error = err.Error()
+	}
+	for _, l := range lags {
+		data.Total += l.Lag
+	}
+	data.Groups = services.LagByTopic(lags)
+	data.Lags = services.FilterPartitionLags(lags, data.MinLag)
+	services.SortPartitionLags(data.Lags, data.SortBy, data.SortBy == services.LagSortLag)
+
+	renderGroupsTemplate(w, "topicLag", data)
+}
+
+func (kah *KafAdminHandlers) deleteConsumerGroupHandler(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	groupID := strings.TrimPrefix(r.URL.Path, "/delete-consumer-group/")
+	if groupID == "" || strings.Contains(groupID, "/") {
+		http.Error(w, "Invalid URL", http.StatusBadRequest)
+		return
+	}
+
+	results, err := kah.kafAdmin.DeleteConsumerGroups([]string{groupID})
+	switch {
+	case err != nil:
+		logger.Error("Error deleting consumer group", "group", groupID, "error", err)
+		fmt.Fprintf(w, "Error deleting consumer group '%s'", groupID)
+	case len(results) == 0:
+		fmt.Fprintf(w, "Consumer group '%s' not found", groupID)
+	case results[0].Error.Code() != kafka.ErrNoError:
+		fmt.Fprintf(w, "Consumer group '%s' not deleted: %s", groupID, results[0].Error)
+	default:
+		fmt.Fprintf(w, "Consumer group '%s' deleted successfully!", groupID)
+	}
+}
+
+// resetOffsetsHandler previews an offset reset of a group on GET and commits
+// it on POST. Both render the plan with current and target offsets. The POST
+// carries the previewed targets and is refused when the plan has changed since.
+func (kah *KafAdminHandlers) resetOffsetsHandler(w http.ResponseWriter, r *http.Request) {
+
+	data := models.OffsetResetPlan{
+		Group: formOrQuery(r, "name"),
+		Topic: formOrQuery(r, "topic"),
+		Mode:  formOrQuery(r, "mode"),
+		Value: formOrQuery(r, "value"),
+	}
+	if data.Group == "" {
+		http.Error(w, "Missing group name", http.StatusBadRequest)
+		return
+	}
+
+	spec, err := resetSpecFromRequest(data.Mode, data.Value)
+	if err == nil {
+		data.Plan, err = kah.kafAdmin.PlanOffsetReset(data.Group, data.Topic, spec)
+	}
+	if err == nil && r.Method == http.MethodPost {
+		err = checkResetTargets(r, data.Plan)
+		if err == nil {
+			err = kah.kafAdmin.ResetConsumerGroupOffsets(data.Group, data.Plan)
+			data.Applied = err == nil
+		}
+	}
+	if err != nil {
+		logger.Error("Error resetting consumer group offsets", "group", data.Group, "error", err)
+		data.Error = err.Error()
+	}
+
+	renderGroupsTemplate(w, "offsetResetPlan", data)
+}
+
+// checkResetTargets compares the plan with the targets of the preview the
+// request was sent from.
+func checkResetTargets(r *http.Request, plan []services.OffsetReset) error {
+	targets, err := previewedTargets(r)
+	if err != nil {
+		return err
+	}
+	changed := len(targets) != len(plan)
+	for _, p := range plan {
+		if target, ok := targets[previewKey(p.Topic, p.Partition)]; !ok || target != p.Target {
+			changed = true
+		}
+	}
+	if changed {
+		return errors.New("the offsets changed since the preview, check the new plan and apply it again")
+	}
+	return nil
+}
+
+// previewKey identifies a partition in the target fields of a preview form.
+func previewKey(topic string, partition int32) string {
+	return fmt.Sprintf("%s:%d", topic, partition)
+}
+
+// previewedTargets reads the target fields of a preview form, one
+// topic:partition:offset value per partition.
+func previewedTargets(r *http.Request) (map[string]int64, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	values := r.PostForm["target"]
+	if len(values) == 0 {
+		return nil, errors.New("the request does not carry the previewed offsets, preview again")
+	}
+	targets := make(map[string]int64, len(values))
+	for _, v := range values {
+		i := strings.LastIndex(v, ":")
+		offset, err := strconv.ParseInt(v[i+1:], 10, 64)
+		if i < 0 || err != nil {
+			return nil, fmt.Errorf("invalid previewed offset '%s'", v)
+		}
+		targets[v[:i]] = offset
+	}
+	return targets, nil
+}
+
+var resetModes = []string{services.ResetToEarliest, services.ResetToLatest, services.ResetToDatetime,
+	services.ResetShiftBy, services.ResetToOffset}
+
+// resetSpecFromRequest builds the reset from the selected mode and its value.
+// Datetimes without a zone are taken as UTC.
+func resetSpecFromRequest(mode, value string) (services.OffsetResetSpec, error) {
+	spec := services.OffsetResetSpec{Mode: mode}
+	var err error
+	switch mode {
+	case services.ResetToEarliest, services.ResetToLatest:
+	case services.ResetToDatetime:
+		spec.Time, err = services.ParseWindowTime(value)
+	case services.ResetShiftBy:
+		spec.Shift, err = strconv.ParseInt(value, 10, 64)
+	case services.ResetToOffset:
+		spec.Offset, err = strconv.ParseInt(value, 10, 64)
+	default:
+		return spec, fmt.Errorf("unknown reset mode '%s'", mode)
+	}
+	if err != nil {
+		return spec, fmt.Errorf("invalid value for %s: %w", mode, err)
+	}
+	return spec, nil
+}
+
+func renderGroupsTemplate(w http.ResponseWriter, name string, data any) {
+	files := []string{BASE_TEMPL_PATH, GROUPS_TEMPL_PATH}
+	tmpl := template.Must(template.New(name).Funcs(groupsFuncMap).ParseFiles(files...))
+
+	err := tmpl.ExecuteTemplate(w, name, data)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal server error", 500)
+		return
+	}
+}
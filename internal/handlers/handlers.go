@@ -18,6 +18,13 @@ const TOPIC_DETAILS_TEMPL_PATH string = "./web/ui/topicdetails.html"
 const MESSAGE_TEMPL_PATH string = "./web/ui/messages.html"
 const VIEW_TOPIC_TEMPL_PATH string = "./web/ui/view_topic.html"
 const PUBLISH_FORM_TEMPL_PATH string = "./web/ui/publishform.html"
+const TAIL_TEMPL_PATH string = "./web/ui/tail.html"
+const GROUPS_TEMPL_PATH string = "./web/ui/groups.html"
+const TOPIC_CONFIG_TEMPL_PATH string = "./web/ui/topicconfig.html"
+const BROKERS_TEMPL_PATH string = "./web/ui/brokers.html"
+const ACLS_TEMPL_PATH string = "./web/ui/acls.html"
+const USERS_TEMPL_PATH string = "./web/ui/users.html"
+const GENERATE_TEMPL_PATH string = "./web/ui/generate.html"
 
 type KafAdminHandlers struct {
 	kafAdmin services.IKafAdmin
@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"html/template"
 	"kafctl/internal/logger"
 	"kafctl/internal/models"
+	"kafctl/internal/services"
 	"log"
 	"net/http"
 	"strconv"
@@ -21,7 +23,8 @@ func (kah *KafAdminHandlers) createTopicFormHandler(w http.ResponseWriter, r *ht
 
 	tmpl := template.Must(template.New("createform").Funcs(funcMap).ParseFiles(files...))
 
-	err := tmpl.ExecuteTemplate(w, "createTopicForm", nil)
+	data := map[string]any{"Presets": services.TopicPresets, "PresetNames": services.TopicPresetNames()}
+	err := tmpl.ExecuteTemplate(w, "createTopicForm", data)
 	if err != nil {
 		log.Println(err)
 		http.Error(w, "Internal server error", 500)
@@ -70,12 +73,29 @@ func (kah *KafAdminHandlers) createTopicHandler(w http.ResponseWriter, r *http.R
 			return
 		}
 
-		err = kah.kafAdmin.CreateTopic(topicName, numPartitions, numReplicas)
+		config, err := topicConfigFromForm(r)
+		if err != nil {
+			fmt.Fprintf(w, "Error: %v", err)
+			return
+		}
+		validateOnly := r.FormValue("validateOnly") == "true"
+
+		err = kah.kafAdmin.CreateTopicWithConfig(services.TopicSpec{
+			Name:              topicName,
+			Partitions:        numPartitions,
+			ReplicationFactor: numReplicas,
+			Config:            config,
+			ValidateOnly:      validateOnly,
+		})
 		if err != nil {
 			fmt.Fprintf(w, "Error creating topic: %v", err)
 			return
 		}
 
+		if validateOnly {
+			fmt.Fprintf(w, "Topic '%s' is valid, nothing was created.", topicName)
+			return
+		}
 		fmt.Fprintf(w, "Topic '%s' created successfully!", topicName)
 	} else {
 		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
@@ -83,6 +103,24 @@ func (kah *KafAdminHandlers) createTopicHandler(w http.ResponseWriter, r *http.R
 
 }
 
+// topicConfigFromForm merges the checked presets with the configs given one
+// name=value per line.
+func topicConfigFromForm(r *http.Request) (map[string]string, error) {
+	configs := make(map[string]string)
+	for _, line := range strings.Split(r.FormValue("configs"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		change, err := services.ParseConfigChange(services.ConfigSet, line)
+		if err != nil {
+			return nil, err
+		}
+		configs[change.Name] = change.Value
+	}
+	return services.TopicConfigFromPresets(r.Form["preset"], configs)
+}
+
 func (kah *KafAdminHandlers) deleteTopicHandler(w http.ResponseWriter, r *http.Request) {
 
 	if r.Method == http.MethodDelete {
@@ -138,6 +176,134 @@ func (kah *KafAdminHandlers) describeTopicHandler(w http.ResponseWriter, r *http
 
 }
 
+// addPartitionsHandler previews raising the partition count of a topic with
+// the current and new count. A POST adds the partitions.
+func (kah *KafAdminHandlers) addPartitionsHandler(w http.ResponseWriter, r *http.Request) {
+
+	data := models.PartitionsPlan{Topic: formOrQuery(r, "name")}
+	if data.Topic == "" {
+		http.Error(w, "Missing topic name", http.StatusBadRequest)
+		return
+	}
+
+	topics, err := kah.kafAdmin.DescribeTopic(data.Topic)
+	if err == nil && len(topics.TopicDescriptions) > 0 {
+		data.Current = len(topics.TopicDescriptions[0].Partitions)
+	}
+	if err == nil {
+		if data.Target, err = strconv.Atoi(formOrQuery(r, "partitions")); err != nil {
+			err = fmt.Errorf("invalid partition count '%s'", formOrQuery(r, "partitions"))
+		}
+	}
+	if err == nil && data.Target <= data.Current {
+		err = fmt.Errorf("topic '%s' already has %d partitions, the new count must be higher", data.Topic, data.Current)
+	}
+	if err == nil && r.Method == http.MethodPost {
+		err = kah.kafAdmin.CreatePartitions(data.Topic, data.Target, false)
+		data.Applied = err == nil
+	}
+	if err != nil {
+		logger.Error("Error adding partitions", "topic", data.Topic, "error", err)
+		data.Error = err.Error()
+	}
+
+	tmpl := template.Must(template.New("partitionsPlan").ParseFiles(TOPIC_DETAILS_TEMPL_PATH))
+	if err := tmpl.ExecuteTemplate(w, "partitionsPlan", data); err != nil {
+		log.Println(err)
+		http.Error(w, "Internal server error", 500)
+	}
+}
+
+// purgeHandler previews deleting the records of a topic on GET with the
+// number of messages removed per partition. A POST deletes them.
+func (kah *KafAdminHandlers) purgeHandler(w http.ResponseWriter, r *http.Request) {
+
+	data := models.PurgePlan{
+		Topic:     formOrQuery(r, "name"),
+		Partition: formOrQuery(r, "partition"),
+		Mode:      formOrQuery(r, "mode"),
+		Value:     formOrQuery(r, "value"),
+	}
+	if data.Topic == "" {
+		http.Error(w, "Missing topic name", http.StatusBadRequest)
+		return
+	}
+
+	spec, err := purgeSpecFromRequest(data.Partition, data.Mode, data.Value)
+	if err == nil {
+		data.Plan, err = kah.kafAdmin.PlanPurge(data.Topic, spec)
+	}
+	if err == nil && r.Method == http.MethodPost {
+		data.Plan, err = previewedPurge(r, data.Plan)
+	}
+	for _, p := range data.Plan {
+		data.Total += p.Count()
+	}
+	if err == nil && r.Method == http.MethodPost {
+		err = kah.kafAdmin.PurgeRecords(data.Plan)
+		data.Applied = err == nil
+	}
+	if err != nil {
+		logger.Error("Error purging records", "topic", data.Topic, "error", err)
+		data.Error = err.Error()
+	}
+
+	tmpl := template.Must(template.New("purgePlan").ParseFiles(TOPIC_DETAILS_TEMPL_PATH))
+	if err := tmpl.ExecuteTemplate(w, "purgePlan", data); err != nil {
+		log.Println(err)
+		http.Error(w, "Internal server error", 500)
+	}
+}
+
+// previewedPurge sets the targets of a fresh plan to the ones of the preview
+// the request was sent from, so messages produced since are kept. Targets
+// below the earliest offset are raised to it, those records are gone already.
+func previewedPurge(r *http.Request, plan []services.RecordPurge) ([]services.RecordPurge, error) {
+	targets, err := previewedTargets(r)
+	if err != nil {
+		return nil, err
+	}
+	previewed := make([]services.RecordPurge, 0, len(targets))
+	for _, p := range plan {
+		if target, ok := targets[previewKey(p.Topic, p.Partition)]; ok {
+			p.Target = min(max(target, p.Earliest), p.Latest)
+			previewed = append(previewed, p)
+		}
+	}
+	if len(previewed) != len(targets) {
+		return nil, errors.New("the partitions changed since the preview, preview again")
+	}
+	return previewed, nil
+}
+
+// purgeSpecFromRequest builds the purge from the partition, an empty one
+// meaning all, the selected mode and its value. Datetimes without a zone are
+// taken as UTC.
+func purgeSpecFromRequest(partition, mode, value string) (services.PurgeSpec, error) {
+	spec := services.PurgeSpec{Mode: mode, Partition: -1}
+	if partition != "" {
+		p, err := strconv.ParseInt(partition, 10, 32)
+		if err != nil || p < 0 {
+			return spec, fmt.Errorf("invalid partition '%s'", partition)
+		}
+		spec.Partition = int32(p)
+	}
+	var err error
+	switch mode {
+	case services.PurgeAll:
+	case services.PurgeBeforeTime:
+		spec.Time, err = services.ParseWindowTime(value)
+	case services.PurgeBeforeOffset:
+		spec.Offset, err = strconv.ParseInt(value, 10, 64)
+	default:
+		return spec, fmt.Errorf("unknown purge mode '%s'", mode)
+	}
+	if err != nil {
+		return spec, fmt.Errorf("invalid value for %s: %w", mode, err)
+	}
+	return spec, nil
+}
+
 func (kah *KafAdminHandlers) GetTopicsHandler(w http.ResponseWriter, r *http.Request) {
 
 	brokerInfo := models.BrokerInfo{}
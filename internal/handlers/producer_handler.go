@@ -1,13 +1,16 @@
 package handlers
 
 import (
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"kafctl/internal/logger"
 	"kafctl/internal/services"
 	"log"
 	"net/http"
+	"strconv"
 
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 	"github.com/google/uuid"
 )
 
@@ -50,19 +53,75 @@ func publishPayload(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodPost {
 		topicName := r.FormValue("topicName")
 		payload := r.FormValue("payload")
-		optionalHeaders := r.FormValue("optionalHeaders")
-		key := uuid.New().String()
 
-		logger.Info("Publish payload options", "topicName", topicName, "payload", payload, "optionalHeaders", optionalHeaders)
+		logger.Info("Publish payload options", "topicName", topicName, "payloadBytes", len(payload),
+			"keyMode", r.FormValue("keyMode"), "partition", r.FormValue("partition"), "partitioner", r.FormValue("partitioner"))
 
-		err := services.ProduceMessage(topicName, key, optionalHeaders, []byte(payload))
+		spec, err := messageSpecFromRequest(r)
+		if err != nil {
+			fmt.Fprintf(w, "ERROR:%s:%v", topicName, err)
+			return
+		}
+		res, err := services.ProduceMessage(spec)
 		if err != nil {
 			fmt.Fprintf(w, "ERROR:%s:%v", topicName, err)
 			return
 		}
 
-		fmt.Fprintf(w, "SUCCESS:%s", topicName)
+		fmt.Fprintf(w, "SUCCESS:%s:%d:%d", topicName, res.Partition, res.Offset)
 	} else {
 		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
 	}
 }
+
+// messageSpecFromRequest builds the message of the publish form. The key is
+// a random UUID, the given key (which may be empty) or no key at all. An
+// empty partition leaves the choice to the partitioner and timestamps
+// without a zone are taken as UTC. Headers are a JSON array of
+// services.RecordHeader.
+func messageSpecFromRequest(r *http.Request) (services.MessageSpec, error) {
+	spec := services.MessageSpec{
+		Topic:       r.FormValue("topicName"),
+		Value:       []byte(r.FormValue("payload")),
+		Partition:   kafka.PartitionAny,
+		Partitioner: r.FormValue("partitioner"),
+	}
+
+	switch r.FormValue("keyMode") {
+	case "", "uuid":
+		spec.Key = []byte(uuid.New().String())
+	case "custom":
+		spec.Key = []byte(r.FormValue("key"))
+	case "none":
+	default:
+		return spec, fmt.Errorf("unknown key mode '%s'", r.FormValue("keyMode"))
+	}
+
+	if partition := r.FormValue("partition"); partition != "" {
+		p, err := strconv.ParseInt(partition, 10, 32)
+		if err != nil || p < 0 {
+			return spec, fmt.Errorf("invalid partition '%s'", partition)
+		}
+		spec.Partition = int32(p)
+	}
+	if timestamp := r.FormValue("timestamp"); timestamp != "" {
+		t, err := services.ParseWindowTime(timestamp)
+		if err != nil {
+			return spec, err
+		}
+		spec.Timestamp = t
+	}
+
+	if raw := r.FormValue("headers"); raw != "" {
+		var recordHeaders []services.RecordHeader
+		if err := json.Unmarshal([]byte(raw), &recordHeaders); err != nil {
+			return spec, fmt.Errorf("invalid headers: %w", err)
+		}
+		headers, err := services.KafkaHeaders(recordHeaders)
+		if err != nil {
+			return spec, err
+		}
+		spec.Headers = headers
+	}
+	return spec, nil
+}
@@ -21,19 +21,45 @@ func (app *Application) Routes(admin services.IKafAdmin) (http.Handler, error) {
 	mux.HandleFunc("/", handlers.home)
 	mux.HandleFunc("/data", handlers.dataHandler)
 
+	mux.HandleFunc("/brokers", handlers.brokersHandler)
+	mux.HandleFunc("/broker", handlers.brokerHandler)
+
 	mux.HandleFunc("/topics", handlers.GetTopicsHandler)
 	mux.HandleFunc("/createtopicform", handlers.createTopicFormHandler)
 	mux.HandleFunc("/createtopic", handlers.createTopicHandler)
 
 	mux.HandleFunc("/topic-details", handlers.describeTopicHandler)
 	mux.HandleFunc("/delete-topic/", handlers.deleteTopicHandler)
+	mux.HandleFunc("/topic-config", handlers.topicConfigHandler)
+	mux.HandleFunc("/add-partitions", handlers.addPartitionsHandler)
+	mux.HandleFunc("/purge", handlers.purgeHandler)
+
+	mux.HandleFunc("/consumer-groups", handlers.consumerGroupsHandler)
+	mux.HandleFunc("/consumer-group", handlers.consumerGroupHandler)
+	mux.HandleFunc("/topic-lag", handlers.topicLagHandler)
+	mux.HandleFunc("/reset-offsets", handlers.resetOffsetsHandler)
+	mux.HandleFunc("/delete-consumer-group/", handlers.deleteConsumerGroupHandler)
+
+	mux.HandleFunc("/acls", handlers.aclsHandler)
+	mux.HandleFunc("/acl-list", handlers.aclListHandler)
+	mux.HandleFunc("/delete-acl", handlers.deleteACLHandler)
+
+	mux.HandleFunc("/users", handlers.usersHandler)
+	mux.HandleFunc("/user-list", handlers.userListHandler)
+	mux.HandleFunc("/delete-user", handlers.deleteUserHandler)
 
 	mux.HandleFunc("/view-topic", consumerHandler.ViewTopic)
 	mux.HandleFunc("/view-messages", consumerHandler.ViewMessages)
+	mux.HandleFunc("/tail-view", consumerHandler.TailView)
+	mux.HandleFunc("/tail", consumerHandler.TailMessages)
 
 	mux.HandleFunc("/publishform", publishForm)
 	mux.HandleFunc("/publishpayload", publishPayload)
 
+	mux.HandleFunc("/generate", handlers.generateHandler)
+	mux.HandleFunc("/generate-preview", generatePreviewHandler)
+	mux.HandleFunc("/generate-stream", generateStreamHandler)
+
 	// Register pprof handlers
 	// mux.HandleFunc("/debug/pprof/", http.HandlerFunc(pprof.Index))
 	// mux.HandleFunc("/debug/pprof/cmdline", http.HandlerFunc(pprof.Cmdline))
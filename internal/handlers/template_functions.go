@@ -37,3 +37,12 @@ func incrementer() func() int {
 		return i
 	}
 }
+
+// countAssigned returns the number of partitions assigned to a group's members.
+func countAssigned(members []kafka.MemberDescription) int {
+	count := 0
+	for _, m := range members {
+		count += len(m.Assignment.TopicPartitions)
+	}
+	return count
+}
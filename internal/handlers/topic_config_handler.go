@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"html/template"
+	"kafctl/internal/logger"
+	"kafctl/internal/models"
+	"kafctl/internal/services"
+	"log"
+	"net/http"
+)
+
+// topicConfigHandler renders the config table of a topic. A POST applies one
+// change (op set, delete or append of key and value) first.
+func (kah *KafAdminHandlers) topicConfigHandler(w http.ResponseWriter, r *http.Request) {
+
+	data := models.TopicConfig{
+		Topic:     formOrQuery(r, "name"),
+		Overrides: formOrQuery(r, "overrides") == "on",
+	}
+	if data.Topic == "" {
+		http.Error(w, "Missing topic name", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		change, err := configChangeFromForm(r)
+		if err == nil {
+			err = kah.kafAdmin.AlterTopicConfig(data.Topic, []services.ConfigChange{change})
+		}
+		if err != nil {
+			logger.Error("Error altering topic config", "topic", data.Topic, "error", err)
+			data.Error = err.Error()
+		} else {
+			data.Message = "Applied " + change.String()
+		}
+	}
+
+	configs, err := kah.kafAdmin.DescribeTopicConfig(data.Topic)
+	if err != nil {
+		logger.Error("Error describing topic config", "topic", data.Topic, "error", err)
+		data.Error = err.Error()
+	}
+	for _, c := range configs {
+		if !data.Overrides || !c.Default {
+			data.Configs = append(data.Configs, c)
+		}
+	}
+
+	tmpl := template.Must(template.New("topicConfig").ParseFiles(TOPIC_CONFIG_TEMPL_PATH))
+	if err := tmpl.ExecuteTemplate(w, "topicConfig", data); err != nil {
+		log.Println(err)
+		http.Error(w, "Internal server error", 500)
+	}
+}
+
+// configChangeFromForm reads op, key and value the same way the CLI parses
+// its config flags.
+func configChangeFromForm(r *http.Request) (services.ConfigChange, error) {
+	op, s := r.FormValue("op"), r.FormValue("key")
+	if op != services.ConfigDelete {
+		s += "=" + r.FormValue("value")
+	}
+	return services.ParseConfigChange(op, s)
+}
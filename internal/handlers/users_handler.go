@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"html/template"
+	"kafctl/internal/logger"
+	"kafctl/internal/models"
+	"kafctl/internal/services"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// Event sent with HX-Trigger after a credential was stored or deleted,
+// reloading the list on the page.
+const usersChangedEvent = "usersChanged"
+
+var usersFuncMap = template.FuncMap{
+	"countPartitions": countPartitions,
+	"countReplicas":   countReplicas,
+	"countIsrs":       countIsrs,
+	"scramMechanisms": func() []string { return services.ScramMechanisms },
+	"scramIterations": func() int { return services.DefaultScramIterations },
+	"userVals":        userVals,
+}
+
+// usersHandler renders the SCRAM users page. A POST creates the credential of
+// user for mechanism or replaces its password. Form values are never logged
+// since they hold the password.
+func (kah *KafAdminHandlers) usersHandler(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodPost {
+		renderUsersTemplate(w, "users", models.ScramUsers{})
+		return
+	}
+
+	user, mechanism := r.FormValue("user"), r.FormValue("mechanism")
+	iterations, err := strconv.Atoi(r.FormValue("iterations"))
+	if err != nil {
+		iterations = services.DefaultScramIterations
+	}
+
+	data := models.ScramUsers{}
+	err = kah.kafAdmin.UpsertUserScramCredential(user, mechanism, iterations, []byte(r.FormValue("password")))
+	if err != nil {
+		logger.Error("Error upserting SCRAM user", "user", user, "mechanism", mechanism, "error", err)
+		data.Error = err.Error()
+	} else {
+		data.Message = "Stored " + strings.ToUpper(mechanism) + " credential of user " + user
+		w.Header().Set("HX-Trigger", usersChangedEvent)
+	}
+	renderUsersTemplate(w, "userResult", data)
+}
+
+// userListHandler renders every user with SCRAM credentials.
+func (kah *KafAdminHandlers) userListHandler(w http.ResponseWriter, r *http.Request) {
+
+	data := models.ScramUsers{}
+	users, err := kah.kafAdmin.DescribeUserScramCredentials(nil)
+	if err != nil {
+		logger.Error("Error listing SCRAM users", "error", err)
+		data.Error = err.Error()
+	}
+	data.Users = users
+	renderUsersTemplate(w, "userList", data)
+}
+
+// deleteUserHandler deletes the credential of user for mechanism.
+func (kah *KafAdminHandlers) deleteUserHandler(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, mechanism := r.FormValue("user"), r.FormValue("mechanism")
+	data := models.ScramUsers{}
+	if err := kah.kafAdmin.DeleteUserScramCredential(user, mechanism); err != nil {
+		logger.Error("Error deleting SCRAM user", "user", user, "mechanism", mechanism, "error", err)
+		data.Error = err.Error()
+	} else {
+		data.Message = "Deleted " + mechanism + " credential of user " + user
+		w.Header().Set("HX-Trigger", usersChangedEvent)
+	}
+	renderUsersTemplate(w, "userResult", data)
+}
+
+// userVals encodes a credential as the hx-vals of its delete button.
+func userVals(user string, mechanism kafka.ScramMechanism) string {
+	vals, _ := json.Marshal(map[string]string{"user": user, "mechanism": mechanism.String()})
+	return string(vals)
+}
+
+func renderUsersTemplate(w http.ResponseWriter, name string, data any) {
+	files := []string{BASE_TEMPL_PATH, USERS_TEMPL_PATH}
+	tmpl := template.Must(template.New(name).Funcs(usersFuncMap).ParseFiles(files...))
+
+	err := tmpl.ExecuteTemplate(w, name, data)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Internal server error", 500)
+		return
+	}
+}
@@ -1,6 +1,10 @@
 package models
 
-import "github.com/confluentinc/confluent-kafka-go/v2/kafka"
+import (
+	"kafctl/internal/services"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
 
 type TopicDetails struct {
 	Name                 string
@@ -15,3 +19,99 @@ type BrokerInfo struct {
 	Status  string
 	Topics  map[string]kafka.TopicMetadata
 }
+
+type ConsumerGroups struct {
+	Groups []kafka.ConsumerGroupDescription
+	Error  string
+}
+
+type ConsumerGroupDetails struct {
+	Group   kafka.ConsumerGroupDescription
+	Offsets []kafka.TopicPartition
+	Error   string
+}
+
+type TopicLag struct {
+	Topic   string
+	Lags    []services.PartitionLag
+	Groups  []services.TopicLag
+	Total   int64
+	SortBy  string
+	SortBys []string
+	MinLag  int64
+	Error   string
+}
+
+type OffsetResetPlan struct {
+	Group   string
+	Topic   string
+	Mode    string
+	Value   string
+	Plan    []services.OffsetReset
+	Applied bool
+	Error   string
+}
+
+type PartitionsPlan struct {
+	Topic   string
+	Current int
+	Target  int
+	Applied bool
+	Error   string
+}
+
+type PurgePlan struct {
+	Topic     string
+	Partition string
+	Mode      string
+	Value     string
+	Plan      []services.RecordPurge
+	Total     int64
+	Applied   bool
+	Error     string
+}
+
+type ClusterDetails struct {
+	ClusterID  string
+	Controller int
+	Brokers    []kafka.Node
+	Diffs      []services.ConfigDiff
+	All        bool
+	Error      string
+}
+
+type BrokerDetails struct {
+	Broker     kafka.Node
+	ClusterID  string
+	Controller bool
+	Configs    []services.ConfigValue
+	Overrides  bool
+	Error      string
+}
+
+type ACLs struct {
+	Filter  services.ACLSpec
+	ACLs    []kafka.ACLBinding
+	Message string
+	Error   string
+}
+
+type ScramUsers struct {
+	Users   []kafka.UserScramCredentialsDescription
+	Message string
+	Error   string
+}
+
+type TopicConfig struct {
+	Topic     string
+	Configs   []services.ConfigValue
+	Overrides bool
+	Message   string
+	Error     string
+}
+
+type Generate struct {
+	Topics  []string
+	Preview []services.GeneratedMessage
+	Error   string
+}
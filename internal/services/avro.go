@@ -0,0 +1,357 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// avroSchema is the schema of MessageRecord in Avro object container files.
+// Keys, values and header values are written as raw bytes.
+const avroSchema = `{"type":"record","name":"MessageRecord","namespace":"kafctl","fields":[` +
+	`{"name":"topic","type":"string"},` +
+	`{"name":"partition","type":"int"},` +
+	`{"name":"offset","type":"long"},` +
+	`{"name":"timestamp","type":{"type":"long","logicalType":"timestamp-millis"}},` +
+	`{"name":"timestampType","type":"string"},` +
+	`{"name":"key","type":["null","bytes"]},` +
+	`{"name":"headers","type":{"type":"array","items":{"type":"record","name":"Header","fields":[` +
+	`{"name":"key","type":"string"},{"name":"value","type":["null","bytes"]}]}}},` +
+	`{"name":"value","type":["null","bytes"]}]}`
+
+var avroMagic = []byte{'O', 'b', 'j', 1}
+
+// Records buffered before a data block is written.
+const avroBlockSize = 100
+
+// avroWriter writes an Avro object container file with the null codec.
+type avroWriter struct {
+	w     io.Writer
+	sync  [16]byte
+	block bytes.Buffer
+	count int64
+}
+
+func newAvroWriter(w io.Writer) (*avroWriter, error) {
+	aw := &avroWriter{w: w}
+	if _, err := rand.Read(aw.sync[:]); err != nil {
+		return nil, err
+	}
+
+	var header bytes.Buffer
+	header.Write(avroMagic)
+	// File metadata is a map with a single block of two entries.
+	writeAvroLong(&header, 2)
+	writeAvroString(&header, "avro.schema")
+	writeAvroBytes(&header, []byte(avroSchema))
+	writeAvroString(&header, "avro.codec")
+	writeAvroBytes(&header, []byte("null"))
+	writeAvroLong(&header, 0)
+	header.Write(aw.sync[:])
+
+	if _, err := w.Write(header.Bytes()); err != nil {
+		return nil, err
+	}
+	return aw, nil
+}
+
+func (aw *avroWriter) Write(rec *MessageRecord) error {
+	key, err := rec.KeyBytes()
+	if err != nil {
+		return err
+	}
+	value, err := rec.ValueBytes()
+	if err != nil {
+		return err
+	}
+	headers, err := rec.KafkaHeaders()
+	if err != nil {
+		return err
+	}
+
+	b := &aw.block
+	writeAvroString(b, rec.Topic)
+	writeAvroLong(b, int64(rec.Partition))
+	writeAvroLong(b, rec.Offset)
+	writeAvroLong(b, rec.Timestamp.UnixMilli())
+	writeAvroString(b, rec.TimestampType)
+	writeAvroNullableBytes(b, key)
+	if len(headers) > 0 {
+		writeAvroLong(b, int64(len(headers)))
+		for _, h := range headers {
+			writeAvroString(b, h.Key)
+			writeAvroNullableBytes(b, h.Value)
+		}
+	}
+	writeAvroLong(b, 0)
+	writeAvroNullableBytes(b, value)
+
+	aw.count++
+	if aw.count >= avroBlockSize {
+		return aw.flushBlock()
+	}
+	return nil
+}
+
+func (aw *avroWriter) Close() error {
+	return aw.flushBlock()
+}
+
+func (aw *avroWriter) flushBlock() error {
+	if aw.count == 0 {
+		return nil
+	}
+
+	var prefix bytes.Buffer
+	writeAvroLong(&prefix, aw.count)
+	writeAvroLong(&prefix, int64(aw.block.Len()))
+
+	for _, data := range [][]byte{prefix.Bytes(), aw.block.Bytes(), aw.sync[:]} {
+		if _, err := aw.w.Write(data); err != nil {
+			return err
+		}
+	}
+	aw.block.Reset()
+	aw.count = 0
+	return nil
+}
+
+// avroReader reads Avro object container files written by avroWriter.
+type avroReader struct {
+	r         *bufio.Reader
+	sync      [16]byte
+	remaining int64
+}
+
+func newAvroReader(r io.Reader) (*avroReader, error) {
+	ar := &avroReader{r: bufio.NewReader(r)}
+
+	magic := make([]byte, len(avroMagic))
+	if _, err := io.ReadFull(ar.r, magic); err != nil || !bytes.Equal(magic, avroMagic) {
+		return nil, fmt.Errorf("not an Avro object container file")
+	}
+
+	meta := make(map[string][]byte)
+	for {
+		count, err := ar.readLong()
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			break
+		}
+		if count < 0 {
+			// A negative count is followed by the block size in bytes.
+			count = -count
+			if _, err := ar.readLong(); err != nil {
+				return nil, err
+			}
+		}
+		for i := int64(0); i < count; i++ {
+			key, err := ar.readString()
+			if err != nil {
+				return nil, err
+			}
+			value, err := ar.readBytes()
+			if err != nil {
+				return nil, err
+			}
+			meta[key] = value
+		}
+	}
+
+	if codec := string(meta["avro.codec"]); codec != "" && codec != "null" {
+		return nil, fmt.Errorf("unsupported Avro codec %q", codec)
+	}
+	if !strings.Contains(string(meta["avro.schema"]), `"name":"MessageRecord","namespace":"kafctl"`) {
+		return nil, fmt.Errorf("unsupported Avro schema, expected a kafctl export file")
+	}
+
+	if _, err := io.ReadFull(ar.r, ar.sync[:]); err != nil {
+		return nil, err
+	}
+	return ar, nil
+}
+
+func (ar *avroReader) Read() (*MessageRecord, error) {
+	if ar.remaining == 0 {
+		count, err := ar.readLong()
+		if err != nil {
+			// A clean end of file is only valid between blocks.
+			return nil, err
+		}
+		if _, err := ar.readLong(); err != nil {
+			return nil, noEOF(err)
+		}
+		if count <= 0 {
+			return nil, fmt.Errorf("corrupt Avro file: invalid block count %d", count)
+		}
+		ar.remaining = count
+	}
+
+	rec, err := ar.readRecord()
+	if err != nil {
+		return nil, noEOF(err)
+	}
+
+	ar.remaining--
+	if ar.remaining == 0 {
+		var sync [16]byte
+		if _, err := io.ReadFull(ar.r, sync[:]); err != nil {
+			return nil, noEOF(err)
+		}
+		if sync != ar.sync {
+			return nil, fmt.Errorf("corrupt Avro file: sync marker mismatch")
+		}
+	}
+	return rec, nil
+}
+
+func (ar *avroReader) readRecord() (*MessageRecord, error) {
+	topic, err := ar.readString()
+	if err != nil {
+		return nil, err
+	}
+	msg := &kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic}}
+
+	partition, err := ar.readLong()
+	if err != nil {
+		return nil, err
+	}
+	msg.TopicPartition.Partition = int32(partition)
+
+	offset, err := ar.readLong()
+	if err != nil {
+		return nil, err
+	}
+	msg.TopicPartition.Offset = kafka.Offset(offset)
+
+	millis, err := ar.readLong()
+	if err != nil {
+		return nil, err
+	}
+	msg.Timestamp = time.UnixMilli(millis).UTC()
+
+	timestampType, err := ar.readString()
+	if err != nil {
+		return nil, err
+	}
+
+	if msg.Key, err = ar.readNullableBytes(); err != nil {
+		return nil, err
+	}
+
+	for {
+		count, err := ar.readLong()
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			break
+		}
+		if count < 0 {
+			count = -count
+			if _, err := ar.readLong(); err != nil {
+				return nil, err
+			}
+		}
+		for i := int64(0); i < count; i++ {
+			var h kafka.Header
+			if h.Key, err = ar.readString(); err != nil {
+				return nil, err
+			}
+			if h.Value, err = ar.readNullableBytes(); err != nil {
+				return nil, err
+			}
+			msg.Headers = append(msg.Headers, h)
+		}
+	}
+
+	if msg.Value, err = ar.readNullableBytes(); err != nil {
+		return nil, err
+	}
+
+	rec := NewMessageRecord(msg)
+	rec.TimestampType = timestampType
+	return &rec, nil
+}
+
+func (ar *avroReader) readLong() (int64, error) {
+	return binary.ReadVarint(ar.r)
+}
+
+func (ar *avroReader) readBytes() ([]byte, error) {
+	n, err := ar.readLong()
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("corrupt Avro file: negative length %d", n)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(ar.r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (ar *avroReader) readString() (string, error) {
+	data, err := ar.readBytes()
+	return string(data), err
+}
+
+func (ar *avroReader) readNullableBytes() ([]byte, error) {
+	branch, err := ar.readLong()
+	if err != nil {
+		return nil, err
+	}
+	switch branch {
+	case 0:
+		return nil, nil
+	case 1:
+		return ar.readBytes()
+	default:
+		return nil, fmt.Errorf("corrupt Avro file: invalid union branch %d", branch)
+	}
+}
+
+// noEOF turns an end of file inside a block into an error.
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// writeAvroLong writes a zig-zag encoded variable length integer.
+func writeAvroLong(b *bytes.Buffer, n int64) {
+	var buf [binary.MaxVarintLen64]byte
+	b.Write(buf[:binary.PutVarint(buf[:], n)])
+}
+
+func writeAvroBytes(b *bytes.Buffer, data []byte) {
+	writeAvroLong(b, int64(len(data)))
+	b.Write(data)
+}
+
+func writeAvroString(b *bytes.Buffer, s string) {
+	writeAvroLong(b, int64(len(s)))
+	b.WriteString(s)
+}
+
+// writeAvroNullableBytes writes a ["null","bytes"] union.
+func writeAvroNullableBytes(b *bytes.Buffer, data []byte) {
+	if data == nil {
+		writeAvroLong(b, 0)
+		return
+	}
+	writeAvroLong(b, 1)
+	writeAvroBytes(b, data)
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"kafctl/internal/logger"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// Input formats of a bulk produce. Lines are one value per line, JSON is an
+// array of {key, headers, value} objects and CSV has a header row naming the
+// key, value and optional headers columns, with CSVNull for a null cell.
+const (
+	BulkLines = "lines"
+	BulkJSON  = FormatJSON
+	BulkCSV   = FormatCSV
+)
+
+var BulkFormats = []string{BulkLines, BulkJSON, BulkCSV}
+
+// Longest line read by the lines format
+const bulkMaxLineBytes = 10 * 1024 * 1024
+
+// BulkOptions controls publishing the messages of a file. An empty Format is
+// derived from the file name. Partition, which must be set to
+// kafka.PartitionAny unless all messages go to one partition, and
+// Partitioner apply to every message and Headers are added before the
+// headers of each message.
+type BulkOptions struct {
+	InputFile   string
+	Format      string
+	Topic       string
+	Partition   int32
+	Partitioner string
+	Headers     []kafka.Header
+	Limits      ProduceLimits
+}
+
+// BulkFormatFromFileName picks the bulk format of a file by its extension,
+// lines unless it is .json or .csv.
+func BulkFormatFromFileName(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return BulkJSON
+	case ".csv":
+		return BulkCSV
+	}
+	return BulkLines
+}
+
+// NewBulkReader returns the reader of a bulk input format.
+func NewBulkReader(format string, r io.Reader) (RecordReader, error) {
+	switch format {
+	case BulkLines:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), bulkMaxLineBytes)
+		return &linesReader{scanner: scanner}, nil
+	case BulkJSON:
+		return &bulkJSONReader{dec: json.NewDecoder(r)}, nil
+	case BulkCSV:
+		return newCSVReader(r), nil
+	}
+	return nil, fmt.Errorf("unknown input format %q, expected one of %s", format, strings.Join(BulkFormats, ", "))
+}
+
+// ProduceBulk publishes every message of the input file to the topic within
+// the limits of opts, until the file ends or ctx is done.
+func ProduceBulk(ctx context.Context, opts BulkOptions) (*ProduceSummary, error) {
+
+	format := opts.Format
+	if format == "" {
+		format = BulkFormatFromFileName(opts.InputFile)
+	}
+
+	file, err := os.Open(opts.InputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open input file %s: %w", opts.InputFile, err)
+	}
+	defer file.Close()
+
+	reader, err := NewBulkReader(format, bufio.NewReader(file))
+	if err != nil {
+		return nil, err
+	}
+
+	producer, err := NewKafProducer()
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Producing file", "file", opts.InputFile, "format", format, "topic", opts.Topic,
+		"rate", opts.Limits.Rate, "inFlight", opts.Limits.InFlight)
+
+	summary, err := produceRecords(ctx, opts.InputFile, reader, func(rec *MessageRecord) (*kafka.Message, error) {
+		key, err := rec.KeyBytes()
+		if err != nil {
+			return nil, err
+		}
+		value, err := rec.ValueBytes()
+		if err != nil {
+			return nil, err
+		}
+		// headers of the input are checked like those given by a user
+		headers, err := KafkaHeaders(rec.Headers)
+		if rec.Encoding != "" {
+			headers, err = rec.KafkaHeaders()
+		}
+		if err != nil {
+			return nil, err
+		}
+		return producer.NewMessage(MessageSpec{
+			Topic:       opts.Topic,
+			Key:         key,
+			Value:       value,
+			Headers:     append(append([]kafka.Header{}, opts.Headers...), headers...),
+			Partition:   opts.Partition,
+			Partitioner: opts.Partitioner,
+		})
+	}, opts.Limits, nil)
+	if summary != nil {
+		logger.Info("Produced file", "file", opts.InputFile, "read", summary.Read,
+			"delivered", summary.Delivered, "failed", summary.Failed, "elapsed", summary.Elapsed)
+	}
+	return summary, err
+}
+
+// linesReader reads one value per line without key. Empty lines are skipped.
+type linesReader struct {
+	scanner *bufio.Scanner
+}
+
+func (lr *linesReader) Read() (*MessageRecord, error) {
+	for lr.scanner.Scan() {
+		line := strings.TrimSuffix(lr.scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		return &MessageRecord{Value: &line}, nil
+	}
+	if err := lr.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// bulkMessage is one object of the JSON format. The value is a string, null
+// or any other JSON value, which is sent as its JSON text.
+type bulkMessage struct {
+	Key     *string         `json:"key"`
+	Headers []RecordHeader  `json:"headers"`
+	Value   json.RawMessage `json:"value"`
+}
+
+type bulkJSONReader struct {
+	dec     *json.Decoder
+	started bool
+}
+
+func (jr *bulkJSONReader) Read() (*MessageRecord, error) {
+	if !jr.started {
+		tok, err := jr.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+			return nil, fmt.Errorf("expected a JSON array of messages")
+		}
+		jr.started = true
+	}
+
+	if !jr.dec.More() {
+		return nil, io.EOF
+	}
+	var msg bulkMessage
+	if err := jr.dec.Decode(&msg); err != nil {
+		return nil, err
+	}
+
+	rec := &MessageRecord{Key: msg.Key, Headers: msg.Headers}
+	switch {
+	case len(msg.Value) == 0 || string(msg.Value) == "null":
+	case msg.Value[0] == '"':
+		var s string
+		if err := json.Unmarshal(msg.Value, &s); err != nil {
+			return nil, err
+		}
+		rec.Value = &s
+	default:
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, msg.Value); err != nil {
+			return nil, err
+		}
+		s := compact.String()
+		rec.Value = &s
+	}
+	return rec, nil
+}
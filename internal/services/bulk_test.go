@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"kafctl/internal/services/mocks"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func readAll(t *testing.T, reader RecordReader) []*MessageRecord {
+	var recs []*MessageRecord
+	for {
+		rec, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return recs
+		}
+		assert.NoError(t, err)
+		recs = append(recs, rec)
+	}
+}
+
+func Test_NewBulkReader(t *testing.T) {
+
+	reader, err := NewBulkReader(BulkLines, strings.NewReader("first\r\n\nsecond, with comma\n"))
+	assert.NoError(t, err)
+	recs := readAll(t, reader)
+	assert.Len(t, recs, 2)
+	assert.Nil(t, recs[0].Key)
+	assert.Equal(t, "first", *recs[0].Value)
+	assert.Equal(t, "second, with comma", *recs[1].Value)
+
+	reader, err = NewBulkReader(BulkJSON, strings.NewReader(`[
+		{"key": "k1", "value": "plain", "headers": [{"key": "h", "value": "00ff", "encoding": "hex"}]},
+		{"value": {"id": 1, "tags": ["a"]}},
+		{"key": "k3", "value": null}
+	]`))
+	assert.NoError(t, err)
+	recs = readAll(t, reader)
+	assert.Len(t, recs, 3)
+	assert.Equal(t, "plain", *recs[0].Value)
+	headers, err := KafkaHeaders(recs[0].Headers)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0, 0xff}, headers[0].Value)
+	assert.Nil(t, recs[1].Key)
+	assert.Equal(t, `{"id":1,"tags":["a"]}`, *recs[1].Value)
+	assert.Nil(t, recs[2].Value)
+
+	reader, err = NewBulkReader(BulkCSV, strings.NewReader("key,value\nk1,\"a,b\"\n\\N,v2\n,\n"))
+	assert.NoError(t, err)
+	recs = readAll(t, reader)
+	assert.Len(t, recs, 3)
+	assert.Equal(t, "k1", *recs[0].Key)
+	assert.Equal(t, "a,b", *recs[0].Value)
+	assert.Nil(t, recs[1].Key)
+	assert.Equal(t, "", *recs[2].Key)
+	assert.Equal(t, "", *recs[2].Value)
+
+	_, err = NewBulkReader("avro", strings.NewReader(""))
+	assert.ErrorContains(t, err, "unknown input format")
+
+	assert.Equal(t, BulkJSON, BulkFormatFromFileName("seed.JSON"))
+	assert.Equal(t, BulkCSV, BulkFormatFromFileName("seed.csv"))
+	assert.Equal(t, BulkLines, BulkFormatFromFileName("seed.jsonl"))
+}
+
+func Test_ProduceBulk(t *testing.T) {
+
+	rdProducer := mocks.NewMockProducer()
+	producer := NewKafProducerFrom(rdProducer)
+	NewKafProducer = func() (IKafProducer, error) { return producer, nil }
+	defer func() { NewKafProducer = CreateKafProducer }()
+
+	// messages alternate between partitions, every third fails and reports
+	// are held back a little to fill the in flight slots
+	var produced, reported, maxInFlight atomic.Int32
+	held := make(chan *kafka.Message, 100)
+	go func() {
+		for msg := range held {
+			time.Sleep(time.Millisecond)
+			reported.Add(1)
+			rdProducer.EventsChan <- msg
+		}
+	}()
+	defer close(held)
+	rdProducer.On("Produce", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		n := produced.Add(1)
+		if cur := n - reported.Load(); cur > maxInFlight.Load() {
+			maxInFlight.Store(cur)
+		}
+		msg := args.Get(0).(*kafka.Message)
+		msg.TopicPartition.Partition = n % 2
+		if n%3 == 0 {
+			msg.TopicPartition.Error = errors.New("record too large")
+		}
+		held <- msg
+	}).Return(nil)
+
+	path := filepath.Join(t.TempDir(), "seed.txt")
+	err := os.WriteFile(path, []byte(strings.Repeat("payload\n", 9)), 0600)
+	assert.NoError(t, err)
+
+	summary, err := ProduceBulk(context.Background(), BulkOptions{
+		InputFile: path,
+		Topic:     "orders",
+		Partition: kafka.PartitionAny,
+		Headers:   []kafka.Header{{Key: "source", Value: []byte("seed")}},
+		Limits:    ProduceLimits{InFlight: 2},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9), summary.Read)
+	assert.Equal(t, int64(6), summary.Delivered)
+	assert.Equal(t, int64(3), summary.Failed)
+	assert.ErrorContains(t, summary.FirstError, "record too large")
+	assert.Equal(t, map[string]map[int32]int64{"orders": {0: 3, 1: 3}}, summary.Partitions)
+	assert.Equal(t, int32(2), maxInFlight.Load())
+
+	msg := rdProducer.Calls[0].Arguments.Get(0).(*kafka.Message)
+	assert.Equal(t, []kafka.Header{{Key: "source", Value: []byte("seed")}}, msg.Headers)
+	assert.Nil(t, msg.Key)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	summary, err = ProduceBulk(ctx, BulkOptions{InputFile: path, Topic: "orders", Partition: kafka.PartitionAny, Limits: ProduceLimits{Rate: 10}})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int64(0), summary.Delivered)
+}
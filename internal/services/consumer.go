@@ -1,11 +1,10 @@
 package services
 
 import (
+	"context"
 	"fmt"
-	"kafctl/internal/config"
 	"kafctl/internal/logger"
 	"log"
-	"os"
 	"sort"
 	"time"
 
@@ -21,15 +20,6 @@ const (
 	overallOperationTimeout = 20 * time.Second
 )
 
-type MessageRecord struct {
-	Topic     string
-	Partition int32
-	Offset    int64
-	Timestamp time.Time
-	Key       string
-	Value     string
-}
-
 type IConsumer interface {
 	GetOffset(topic string, partition int, timeoutMs int) error
 	GetTopicOffsets(topic *string) error
@@ -37,7 +27,9 @@ type IConsumer interface {
 	ConsumeMessage(topic string) ([]*kafka.Message, error)
 	GetLatestRecords(topic string, countPerPartition int) ([]*kafka.Message, error)
 	GetMessagesInfo(topic string) ([]TopicDetails, error)
-	ConsumeMessagesInFile() error
+	ConsumeMessagesInFile(opts ExportOptions) (*ExportSummary, error)
+	ConsumeWindow(topic string, window MessageWindow) (*WindowResult, error)
+	Tail(ctx context.Context, topic string, opts TailOptions, handle func(msg *kafka.Message) error) error
 	Close() error
 }
 
@@ -168,10 +160,11 @@ type TopicDetails struct {
 	Size        int64
 }
 
+// GetMessagesInfo returns the watermarks of every partition of a topic. The
+// consumer stays open so it can be queried for several topics.
 func (c *Consumer) GetMessagesInfo(topic string) ([]TopicDetails, error) {
 
 	consumer := c.consumer
-	defer consumer.Close()
 
 	log.Printf("Consumer created for topic %s", topic)
 
@@ -449,14 +442,7 @@ func (c *Consumer) GetLatestRecords(topic string, countPerPartition int) ([]*kaf
 
 	finalRecords := make([]MessageRecord, 0, len(collectedRawRecords))
 	for _, rec := range collectedRawRecords {
-		finalRecords = append(finalRecords, MessageRecord{
-			Topic:     *rec.TopicPartition.Topic,
-			Partition: rec.TopicPartition.Partition,
-			Offset:    int64(rec.TopicPartition.Offset),
-			Timestamp: rec.Timestamp,
-			Key:       string(rec.Key),   // Assuming UTF-8 string
-			Value:     string(rec.Value), // Assuming UTF-8 string
-		})
+		finalRecords = append(finalRecords, NewMessageRecord(rec))
 	}
 
 	log.Printf("Returning %d deserialized records.", len(finalRecords))
@@ -500,44 +486,6 @@ func (c *Consumer) ConsumeMessages(topic string) error {
 	return nil
 }
 
-func (c *Consumer) ConsumeMessagesInFile() error {
-
-	// Open a file to write the messages
-	file, err := os.Create(config.OutputFile)
-	if err != nil {
-		panic(err)
-	}
-	defer file.Close()
-
-	// Subscribe to the topic
-	c.consumer.SubscribeTopics([]string{config.Topic}, nil)
-
-	fmt.Printf("Consuming from topic: %s, output file: %s\n", config.Topic, config.OutputFile)
-
-	// Consume messages
-	for {
-		msg, err := c.consumer.ReadMessage(-1)
-		if err == nil {
-			// Write message details to the file
-			headers := ""
-			for _, header := range msg.Headers {
-				headers += fmt.Sprintf("%s: %s, ", header.Key, string(header.Value))
-			}
-			fmt.Printf("Offset=%d, Key=%s, TimeStamp=%s \n",
-				msg.TopicPartition.Offset, string(msg.Key), msg.Timestamp)
-
-			_, err := file.WriteString(fmt.Sprintf("Offset=%d, Key=%s, Headers=%s,\nMessage=%s \n\n",
-				msg.TopicPartition.Offset, string(msg.Key), headers, string(msg.Value)))
-			if err != nil {
-				panic(err)
-			}
-		} else {
-			fmt.Printf("Consumer error: %v (%v)\n", err, msg)
-		}
-	}
-
-}
-
 func (c *Consumer) Close() error {
 	err := c.consumer.Close()
 	if err != nil {
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"kafctl/internal/config"
+	"kafctl/internal/logger"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ExportOptions controls a bounded export of a topic into a file.
+// Empty Topic and OutputFile fall back to the values in config, an empty
+// Format is derived from the output file name.
+type ExportOptions struct {
+	Topic      string
+	OutputFile string
+	Format     string
+	// Window restricts the export and may filter it, the whole topic is
+	// exported when nil.
+	Window *MessageWindow
+	// Progress, when set, is called periodically and once more when the export ends.
+	Progress func(RangeProgress)
+}
+
+type ExportSummary struct {
+	Topic      string
+	OutputFile string
+	Format     string
+	Partitions []PartitionRange
+	// Scanned counts the messages read, Exported those that matched the filter.
+	Scanned  int64
+	Exported int64
+	Elapsed  time.Duration
+}
+
+// ConsumeMessagesInFile exports every message of a topic into a file. It
+// snapshots the high watermark of each partition first and stops once all
+// partitions are read up to it, so it terminates on busy topics as well.
+func (c *Consumer) ConsumeMessagesInFile(opts ExportOptions) (*ExportSummary, error) {
+
+	topic := opts.Topic
+	if topic == "" {
+		topic = config.Topic
+	}
+	outputFile := opts.OutputFile
+	if outputFile == "" {
+		outputFile = config.OutputFile
+	}
+	if topic == "" || outputFile == "" {
+		return nil, fmt.Errorf("topic and output file are required for an export")
+	}
+	format := opts.Format
+	if format == "" {
+		format = FormatFromFileName(outputFile)
+	}
+	if !slices.Contains(Formats, format) {
+		return nil, fmt.Errorf("unknown format %q, expected one of %s", format, strings.Join(Formats, ", "))
+	}
+
+	window := NewMessageWindow()
+	if opts.Window != nil {
+		window = *opts.Window
+	}
+	ranges, err := c.resolveRanges(topic, window)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Create(outputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file %s: %w", outputFile, err)
+	}
+	defer file.Close()
+
+	out := bufio.NewWriter(file)
+	records, err := NewRecordWriter(format, out)
+	if err != nil {
+		return nil, err
+	}
+	summary := &ExportSummary{Topic: topic, OutputFile: outputFile, Format: format}
+	startTime := time.Now()
+
+	err = c.readRanges(topic, ranges, window.Filter, func(msg *kafka.Message) error {
+		if window.Limit > 0 && summary.Exported >= int64(window.Limit) {
+			return errStopReading
+		}
+		rec := NewMessageRecord(msg)
+		if err := records.Write(&rec); err != nil {
+			return fmt.Errorf("failed to write message at %s [%d] @ %d: %w",
+				topic, msg.TopicPartition.Partition, msg.TopicPartition.Offset, err)
+		}
+		summary.Exported++
+		return nil
+	}, opts.Progress)
+
+	if closeErr := records.Close(); closeErr != nil && err == nil {
+		err = fmt.Errorf("failed to write output file %s: %w", outputFile, closeErr)
+	}
+	if flushErr := out.Flush(); flushErr != nil && err == nil {
+		err = fmt.Errorf("failed to write output file %s: %w", outputFile, flushErr)
+	}
+
+	summary.Partitions = sortedRanges(ranges)
+	for _, p := range summary.Partitions {
+		summary.Scanned += p.Scanned
+	}
+	summary.Elapsed = time.Since(startTime)
+
+	logger.Info("Export finished", "topic", topic, "file", outputFile, "messages", summary.Exported,
+		"scanned", summary.Scanned, "filter", window.Filter, "elapsed", summary.Elapsed)
+	return summary, err
+}
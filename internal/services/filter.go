@@ -0,0 +1,615 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// MessageFilter is a parsed filter expression that selects messages by key,
+// value, headers, metadata or JSON fields of the value, for example
+//
+//	key == "abc" && header.traceId ~ "^x" && $.order.status == "FAILED"
+//
+// Operands are key, value, topic, partition, offset, timestamp, header.<name>
+// (or header["name"]) and JSON paths into the value like $.items[0].sku.
+// Operators are ==, !=, <, <=, >, >= and the regular expression matches ~ and
+// !~, combined with &&, || and ! and grouped with parentheses. Literals are
+// double quoted strings, numbers, true, false and null. An operand on its own
+// tests that it is present. A comparison against a missing header or JSON
+// field is false, whatever the operator. Timestamps compare against times in
+// any format accepted by ParseWindowTime.
+type MessageFilter struct {
+	expr string
+	root filterNode
+}
+
+// ParseFilter parses a filter expression.
+func ParseFilter(expr string) (*MessageFilter, error) {
+	tokens, err := lexFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{expr: expr, tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorf(tok, "unexpected %q", tok.text)
+	}
+	return &MessageFilter{expr: expr, root: root}, nil
+}
+
+// Match reports whether the message satisfies the filter.
+func (f *MessageFilter) Match(msg *kafka.Message) bool {
+	return f.root.eval(&filterContext{msg: msg})
+}
+
+func (f *MessageFilter) String() string {
+	return f.expr
+}
+
+// filterContext holds the message being evaluated and its value decoded as
+// JSON, decoded at most once per message.
+type filterContext struct {
+	msg       *kafka.Message
+	decoded   bool
+	jsonValue any
+	jsonValid bool
+}
+
+func (ctx *filterContext) json() (any, bool) {
+	if !ctx.decoded {
+		ctx.decoded = true
+		ctx.jsonValid = json.Unmarshal(ctx.msg.Value, &ctx.jsonValue) == nil
+	}
+	return ctx.jsonValue, ctx.jsonValid
+}
+
+type filterNode interface {
+	eval(ctx *filterContext) bool
+}
+
+type andNode struct{ left, right filterNode }
+type orNode struct{ left, right filterNode }
+type notNode struct{ node filterNode }
+
+func (n andNode) eval(ctx *filterContext) bool { return n.left.eval(ctx) && n.right.eval(ctx) }
+func (n orNode) eval(ctx *filterContext) bool  { return n.left.eval(ctx) || n.right.eval(ctx) }
+func (n notNode) eval(ctx *filterContext) bool { return !n.node.eval(ctx) }
+
+// existsNode is an operand used as a predicate on its own.
+type existsNode struct{ operand filterOperand }
+
+func (n existsNode) eval(ctx *filterContext) bool {
+	return len(n.operand.values(ctx)) > 0
+}
+
+// compareNode compares an operand with a literal. Operands with several
+// values, like repeated headers, match when any of their values does.
+type compareNode struct {
+	operand filterOperand
+	op      string
+	literal any
+	re      *regexp.Regexp
+}
+
+func (n compareNode) eval(ctx *filterContext) bool {
+	for _, v := range n.operand.values(ctx) {
+		if n.compare(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n compareNode) compare(v any) bool {
+	if n.re != nil {
+		if v == nil {
+			return n.op == "!~"
+		}
+		return n.re.MatchString(filterString(v)) == (n.op == "~")
+	}
+
+	cmp, ok := compareFilterValues(v, n.literal)
+	switch n.op {
+	case "==":
+		return ok && cmp == 0
+	case "!=":
+		return !ok || cmp != 0
+	case "<":
+		return ok && cmp < 0
+	case "<=":
+		return ok && cmp <= 0
+	case ">":
+		return ok && cmp > 0
+	case ">=":
+		return ok && cmp >= 0
+	}
+	return false
+}
+
+// compareFilterValues orders a message value against a literal. ok is false
+// when the two cannot be compared, which makes every operator but != false.
+func compareFilterValues(v, literal any) (int, bool) {
+	switch lit := literal.(type) {
+	case nil:
+		if v == nil {
+			return 0, true
+		}
+		return 0, false
+	case string:
+		if v == nil {
+			return 0, false
+		}
+		return strings.Compare(filterString(v), lit), true
+	case float64:
+		var f float64
+		switch val := v.(type) {
+		case float64:
+			f = val
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+			if err != nil {
+				return 0, false
+			}
+			f = parsed
+		default:
+			return 0, false
+		}
+		switch {
+		case f < lit:
+			return -1, true
+		case f > lit:
+			return 1, true
+		}
+		return 0, true
+	case bool:
+		// Booleans are only equal or not, never ordered.
+		switch val := v.(type) {
+		case bool:
+			return 0, val == lit
+		case string:
+			return 0, val == strconv.FormatBool(lit)
+		}
+	}
+	return 0, false
+}
+
+// filterString renders a value the way it is matched against strings and
+// regular expressions. JSON objects and arrays are matched as JSON.
+func filterString(v any) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	case nil:
+		return ""
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// filterOperand returns the values an operand has for a message, none when
+// it is missing. A nil value stands for a null key, value or JSON field.
+type filterOperand interface {
+	values(ctx *filterContext) []any
+}
+
+type fieldOperand string
+
+func (f fieldOperand) values(ctx *filterContext) []any {
+	msg := ctx.msg
+	switch f {
+	case "key":
+		if msg.Key == nil {
+			return []any{nil}
+		}
+		return []any{string(msg.Key)}
+	case "value":
+		if msg.Value == nil {
+			return []any{nil}
+		}
+		return []any{string(msg.Value)}
+	case "topic":
+		if msg.TopicPartition.Topic == nil {
+			return nil
+		}
+		return []any{*msg.TopicPartition.Topic}
+	case "partition":
+		return []any{float64(msg.TopicPartition.Partition)}
+	case "offset":
+		return []any{float64(msg.TopicPartition.Offset)}
+	case "timestamp":
+		return []any{float64(msg.Timestamp.UnixMilli())}
+	}
+	return nil
+}
+
+type headerOperand string
+
+func (h headerOperand) values(ctx *filterContext) []any {
+	var res []any
+	for _, header := range ctx.msg.Headers {
+		if header.Key != string(h) {
+			continue
+		}
+		if header.Value == nil {
+			res = append(res, nil)
+		} else {
+			res = append(res, string(header.Value))
+		}
+	}
+	return res
+}
+
+// jsonPathOperand holds the object keys (string) and array indexes (int) of a
+// path into the message value.
+type jsonPathOperand []any
+
+func (p jsonPathOperand) values(ctx *filterContext) []any {
+	cur, ok := ctx.json()
+	if !ok {
+		return nil
+	}
+	for _, seg := range p {
+		switch s := seg.(type) {
+		case string:
+			obj, ok := cur.(map[string]any)
+			if !ok {
+				return nil
+			}
+			if cur, ok = obj[s]; !ok {
+				return nil
+			}
+		case int:
+			arr, ok := cur.([]any)
+			if !ok || s < 0 || s >= len(arr) {
+				return nil
+			}
+			cur = arr[s]
+		}
+	}
+	return []any{cur}
+}
+
+const (
+	tokEOF = iota
+	tokPath
+	tokString
+	tokNumber
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type filterToken struct {
+	kind int
+	text string
+	pos  int
+}
+
+var filterOperators = []string{"&&", "||", "==", "!=", "!~", "<=", ">=", "<", ">", "~", "!"}
+
+func lexFilter(expr string) ([]filterToken, error) {
+	var tokens []filterToken
+	i := 0
+	for i < len(expr) {
+		c, size := utf8.DecodeRuneInString(expr[i:])
+		switch {
+		case unicode.IsSpace(c):
+			i += size
+		case c == '(':
+			tokens = append(tokens, filterToken{tokLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, filterToken{tokRParen, ")", i})
+			i++
+		case c == '"':
+			end, err := scanFilterString(expr, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, filterToken{tokString, expr[i:end], i})
+			i = end
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(expr) && strings.ContainsRune("0123456789.eE+-", rune(expr[end])) {
+				end++
+			}
+			tokens = append(tokens, filterToken{tokNumber, expr[i:end], i})
+			i = end
+		case c == '$' || c == '_' || unicode.IsLetter(c):
+			end, err := scanFilterPath(expr, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, filterToken{tokPath, expr[i:end], i})
+			i = end
+		default:
+			op := ""
+			for _, candidate := range filterOperators {
+				if strings.HasPrefix(expr[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("invalid filter at position %d: unexpected %q", i+1, c)
+			}
+			tokens = append(tokens, filterToken{tokOp, op, i})
+			i += len(op)
+		}
+	}
+	return append(tokens, filterToken{tokEOF, "end of filter", len(expr)}), nil
+}
+
+// scanFilterString returns the end of the double quoted string starting at i.
+func scanFilterString(expr string, i int) (int, error) {
+	for j := i + 1; j < len(expr); j++ {
+		switch expr[j] {
+		case '\\':
+			j++
+		case '"':
+			return j + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid filter at position %d: unterminated string", i+1)
+}
+
+// scanFilterPath returns the end of an operand like header.x-trace-id or
+// $.items[0]["unit price"] starting at i.
+func scanFilterPath(expr string, i int) (int, error) {
+	j := i
+	for j < len(expr) {
+		c, size := utf8.DecodeRuneInString(expr[j:])
+		switch {
+		case c == '[':
+			k := j + 1
+			if k < len(expr) && expr[k] == '"' {
+				end, err := scanFilterString(expr, k)
+				if err != nil {
+					return 0, err
+				}
+				k = end
+			}
+			for k < len(expr) && expr[k] != ']' {
+				k++
+			}
+			if k == len(expr) {
+				return 0, fmt.Errorf("invalid filter at position %d: missing ']'", j+1)
+			}
+			j = k + 1
+		case c == '$' || c == '_' || c == '.' || c == '-' || unicode.IsLetter(c) || unicode.IsDigit(c):
+			j += size
+		default:
+			return j, nil
+		}
+	}
+	return j, nil
+}
+
+type filterParser struct {
+	expr   string
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() filterToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *filterParser) errorf(tok filterToken, format string, args ...any) error {
+	return fmt.Errorf("invalid filter at position %d: %s", tok.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for tok := p.peek(); tok.kind == tokOp && tok.text == "||"; tok = p.peek() {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for tok := p.peek(); tok.kind == tokOp && tok.text == "&&"; tok = p.peek() {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterNode, error) {
+	tok := p.next()
+	switch {
+	case tok.kind == tokOp && tok.text == "!":
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{node}, nil
+
+	case tok.kind == tokLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, p.errorf(closing, "expected ')' but found %q", closing.text)
+		}
+		return node, nil
+
+	case tok.kind == tokPath:
+		return p.parseComparison(tok)
+	}
+	return nil, p.errorf(tok, "expected an operand like key, header.<name> or $.field but found %q", tok.text)
+}
+
+func (p *filterParser) parseComparison(operandTok filterToken) (filterNode, error) {
+	operand, err := parseFilterOperand(operandTok.text)
+	if err != nil {
+		return nil, p.errorf(operandTok, "%v", err)
+	}
+
+	opTok := p.peek()
+	if opTok.kind != tokOp || opTok.text == "&&" || opTok.text == "||" || opTok.text == "!" {
+		return existsNode{operand}, nil
+	}
+	p.next()
+
+	litTok := p.next()
+	var literal any
+	switch litTok.kind {
+	case tokString:
+		s, err := strconv.Unquote(litTok.text)
+		if err != nil {
+			return nil, p.errorf(litTok, "invalid string %s", litTok.text)
+		}
+		literal = s
+	case tokNumber:
+		f, err := strconv.ParseFloat(litTok.text, 64)
+		if err != nil {
+			return nil, p.errorf(litTok, "invalid number %s", litTok.text)
+		}
+		literal = f
+	case tokPath:
+		switch litTok.text {
+		case "true", "false":
+			literal = litTok.text == "true"
+		case "null":
+			literal = nil
+		default:
+			return nil, p.errorf(litTok, "expected a literal but found %q, quote strings with \"", litTok.text)
+		}
+	default:
+		return nil, p.errorf(litTok, "expected a literal after %s but found %q", opTok.text, litTok.text)
+	}
+
+	node := compareNode{operand: operand, op: opTok.text, literal: literal}
+	switch opTok.text {
+	case "~", "!~":
+		s, ok := literal.(string)
+		if !ok {
+			return nil, p.errorf(litTok, "%s expects a quoted regular expression", opTok.text)
+		}
+		if node.re, err = regexp.Compile(s); err != nil {
+			return nil, p.errorf(litTok, "invalid regular expression: %v", err)
+		}
+	}
+
+	if operand == fieldOperand("timestamp") {
+		if s, ok := literal.(string); ok {
+			ts, err := ParseWindowTime(s)
+			if err != nil {
+				return nil, p.errorf(litTok, "%v", err)
+			}
+			node.literal = float64(ts.UnixMilli())
+		}
+	}
+	return node, nil
+}
+
+// parseFilterOperand resolves the text of an operand token.
+func parseFilterOperand(text string) (filterOperand, error) {
+	switch text {
+	case "key", "value", "topic", "partition", "offset", "timestamp":
+		return fieldOperand(text), nil
+	}
+
+	if name, ok := strings.CutPrefix(text, "header"); ok {
+		switch {
+		case strings.HasPrefix(name, ".") && len(name) > 1:
+			return headerOperand(name[1:]), nil
+		case strings.HasPrefix(name, "[\"") && strings.HasSuffix(name, "\"]"):
+			key, err := strconv.Unquote(name[1 : len(name)-1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid header name %s", name)
+			}
+			return headerOperand(key), nil
+		}
+		return nil, fmt.Errorf("expected header.<name> or header[\"name\"] but found %q", text)
+	}
+
+	if rest, ok := strings.CutPrefix(text, "$"); ok {
+		return parseJSONPath(rest)
+	}
+	return nil, fmt.Errorf("unknown operand %q, expected key, value, topic, partition, offset, timestamp, header.<name> or $.path", text)
+}
+
+// parseJSONPath parses the segments following $, like .order.items[0]["unit price"].
+func parseJSONPath(path string) (jsonPathOperand, error) {
+	var segments jsonPathOperand
+	for path != "" {
+		switch path[0] {
+		case '.':
+			end := strings.IndexAny(path[1:], ".[")
+			if end < 0 {
+				end = len(path) - 1
+			}
+			name := path[1 : end+1]
+			if name == "" {
+				return nil, fmt.Errorf("empty field name in JSON path")
+			}
+			segments = append(segments, name)
+			path = path[end+1:]
+		case '[':
+			if strings.HasPrefix(path, "[\"") {
+				closing, err := scanFilterString(path, 1)
+				if err != nil || closing >= len(path) || path[closing] != ']' {
+					return nil, fmt.Errorf("invalid JSON path segment %s", path)
+				}
+				name, err := strconv.Unquote(path[1:closing])
+				if err != nil {
+					return nil, fmt.Errorf("invalid JSON path segment %s", path)
+				}
+				segments = append(segments, name)
+				path = path[closing+1:]
+				continue
+			}
+			end := strings.Index(path, "]")
+			index, err := strconv.Atoi(path[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("invalid array index %q in JSON path", path[1:end])
+			}
+			segments = append(segments, index)
+			path = path[end+1:]
+		default:
+			return nil, fmt.Errorf("invalid JSON path, expected . or [ but found %q", path[0])
+		}
+	}
+	return segments, nil
+}
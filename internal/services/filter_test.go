@@ -0,0 +1,65 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MessageFilterMatch(t *testing.T) {
+	failed, binary := testMessages()[0], testMessages()[1]
+	failed.Value = []byte(`{"order":{"status":"FAILED","items":[{"sku":"a-1","qty":3}]},"unit price":2.5,"città":"Roma","Åland":"x"}`)
+
+	tests := []struct {
+		expr   string
+		failed bool
+		binary bool
+	}{
+		{`key == "order-1"`, true, false},
+		{`key != "order-1"`, false, true},
+		{`key == null`, false, true},
+		{`header.traceId ~ "^x"`, true, false},
+		{`header["traceId"] == "x-1"`, true, false},
+		{`header.traceId != "y"`, true, false},
+		{`header.traceId`, true, false},
+		{`!header.traceId`, false, true},
+		{`$.order.status == "FAILED"`, true, false},
+		{`$.order.items[0].qty >= 3 && $.order.items[0].sku ~ "^a"`, true, false},
+		{`$["unit price"] < 3`, true, false},
+		{`$.città == "Roma"`, true, false},
+		{`$.Åland == "x" && $.città ~ "^R"`, true, false},
+		{`$.order.missing != "x"`, false, false},
+		{`partition == 0 || offset > 40`, true, true},
+		{`!(partition == 0) && value ~ "FAILED"`, true, false},
+		{`timestamp >= "2024-05-01" && timestamp < "2024-05-02T00:00:00Z"`, true, true},
+		{`key == "order-1" && header.traceId ~ "^x" && $.order.status == "FAILED"`, true, false},
+	}
+	for _, tt := range tests {
+		filter, err := ParseFilter(tt.expr)
+		if !assert.NoError(t, err, tt.expr) {
+			continue
+		}
+		assert.Equal(t, tt.failed, filter.Match(failed), tt.expr)
+		assert.Equal(t, tt.binary, filter.Match(binary), tt.expr)
+	}
+}
+
+func Test_ParseFilterErrors(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`key ==`,
+		`key == abc`,
+		`key == "abc`,
+		`(key == "a"`,
+		`header.x ~ "["`,
+		`header == "a"`,
+		`size > 3`,
+		`$.items[x] == 1`,
+		`key == "a" key`,
+		`timestamp > "tomorrow"`,
+		`key ≠ "a"`,
+	} {
+		_, err := ParseFilter(expr)
+		assert.Error(t, err, expr)
+	}
+}
@@ -0,0 +1,334 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"kafctl/internal/logger"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/google/uuid"
+)
+
+// How often the progress of a generator run is reported
+const generateProgressInterval = time.Second
+
+// Characters of the random strings of a template
+const generateAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// GeneratedMessage is a key and value rendered by a Generator. The key is nil
+// without key template.
+type GeneratedMessage struct {
+	Key   *string
+	Value string
+}
+
+// generateData is the dot of the templates. Key is the rendered key, empty in
+// the key template itself.
+type generateData struct {
+	Key string
+}
+
+// Generator renders messages from Go templates. Besides the text/template
+// builtins the templates can use:
+//
+//	uuid                 random UUID
+//	randInt min max      random integer between min and max, both included
+//	randFloat min max    random float between min and max
+//	randString n         n random letters and digits
+//	randBool             true or false
+//	pick a b ...         one of its arguments
+//	seq [start]          number of the message, from 1 or from start
+//	now                  current time, e.g. {{(now).Format "15:04:05"}}
+//	timestamp            current time in RFC 3339 with milliseconds, UTC
+//	unixMs               current time in Unix milliseconds
+//	json v               v as JSON, e.g. a string with quotes and escapes
+//
+// The value template can refer to the rendered key as {{.Key}}. Random values
+// only depend on the seed, so a seed renders the same messages every time.
+type Generator struct {
+	key   *template.Template
+	value *template.Template
+	rnd   *rand.Rand
+	seq   int64
+}
+
+// NewGenerator parses the templates of a generator. An empty key template
+// renders messages without key and a zero seed picks a random one.
+func NewGenerator(keyTemplate, valueTemplate string, seed uint64) (*Generator, error) {
+
+	if valueTemplate == "" {
+		return nil, errors.New("the value template is empty")
+	}
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	g := &Generator{rnd: rand.New(rand.NewPCG(seed, seed))}
+
+	var err error
+	if keyTemplate != "" {
+		g.key, err = template.New("key").Funcs(g.funcs()).Option("missingkey=error").Parse(keyTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key template: %w", err)
+		}
+	}
+	g.value, err = template.New("value").Funcs(g.funcs()).Option("missingkey=error").Parse(valueTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value template: %w", err)
+	}
+	return g, nil
+}
+
+// Next renders the next message.
+func (g *Generator) Next() (GeneratedMessage, error) {
+
+	g.seq++
+	var msg GeneratedMessage
+	var b strings.Builder
+	if g.key != nil {
+		if err := g.key.Execute(&b, generateData{}); err != nil {
+			return msg, err
+		}
+		key := b.String()
+		msg.Key = &key
+		b.Reset()
+	}
+
+	data := generateData{}
+	if msg.Key != nil {
+		data.Key = *msg.Key
+	}
+	if err := g.value.Execute(&b, data); err != nil {
+		return msg, err
+	}
+	msg.Value = b.String()
+	return msg, nil
+}
+
+func (g *Generator) funcs() template.FuncMap {
+	return template.FuncMap{
+		"uuid": func() (string, error) {
+			id, err := uuid.NewRandomFromReader(randReader{g.rnd})
+			return id.String(), err
+		},
+		"randInt": func(min, max int64) (int64, error) {
+			if max < min {
+				return 0, fmt.Errorf("min %d is above max %d", min, max)
+			}
+			return min + g.rnd.Int64N(max-min+1), nil
+		},
+		"randFloat": func(min, max float64) (float64, error) {
+			if max < min {
+				return 0, fmt.Errorf("min %g is above max %g", min, max)
+			}
+			return min + g.rnd.Float64()*(max-min), nil
+		},
+		"randString": func(n int) (string, error) {
+			if n < 0 {
+				return "", fmt.Errorf("negative length %d", n)
+			}
+			b := make([]byte, n)
+			for i := range b {
+				b[i] = generateAlphabet[g.rnd.IntN(len(generateAlphabet))]
+			}
+			return string(b), nil
+		},
+		"randBool": func() bool {
+			return g.rnd.IntN(2) == 1
+		},
+		"pick": func(values ...any) (any, error) {
+			if len(values) == 0 {
+				return nil, errors.New("no values to pick from")
+			}
+			return values[g.rnd.IntN(len(values))], nil
+		},
+		"seq": func(start ...int64) (int64, error) {
+			switch len(start) {
+			case 0:
+				return g.seq, nil
+			case 1:
+				return start[0] + g.seq - 1, nil
+			}
+			return 0, errors.New("expected at most one start value")
+		},
+		"now": time.Now,
+		"timestamp": func() string {
+			return time.Now().UTC().Format("2006-01-02T15:04:05.000Z07:00")
+		},
+		"unixMs": func() int64 {
+			return time.Now().UnixMilli()
+		},
+		"json": func(v any) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}
+}
+
+// randReader reads random bytes from a generator, making its UUIDs depend on
+// the seed.
+type randReader struct {
+	rnd *rand.Rand
+}
+
+func (r randReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(r.rnd.Uint32())
+	}
+	return len(p), nil
+}
+
+// GenerateOptions controls producing generated messages. Zero Count and
+// Duration generate until ctx is done. Partition, which must be set to
+// kafka.PartitionAny unless all messages go to one partition, Partitioner
+// and Headers apply to every message.
+type GenerateOptions struct {
+	Topic         string
+	KeyTemplate   string
+	ValueTemplate string
+	Headers       []kafka.Header
+	Partition     int32
+	Partitioner   string
+	Count         int64
+	Duration      time.Duration
+	Seed          uint64
+	Limits        ProduceLimits
+	// Progress, when not nil, is called about every second while producing.
+	Progress func(GenerateProgress)
+}
+
+// GenerateProgress counts the messages of a generator run so far. Rate is
+// the messages per second delivered since the previous report.
+type GenerateProgress struct {
+	Generated int64         `json:"generated"`
+	Delivered int64         `json:"delivered"`
+	Failed    int64         `json:"failed"`
+	Elapsed   time.Duration `json:"elapsed"`
+	Rate      float64       `json:"rate"`
+}
+
+// GenerateMessages produces messages rendered from the templates of opts
+// until Count messages were sent, Duration has passed or ctx is done, and
+// waits for their delivery reports. Stopping through ctx is not an error.
+func GenerateMessages(ctx context.Context, opts GenerateOptions) (*ProduceSummary, error) {
+
+	gen, err := NewGenerator(opts.KeyTemplate, opts.ValueTemplate, opts.Seed)
+	if err != nil {
+		return nil, err
+	}
+
+	producer, err := NewKafProducer()
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Generating messages", "topic", opts.Topic, "count", opts.Count, "duration", opts.Duration,
+		"rate", opts.Limits.Rate, "inFlight", opts.Limits.InFlight)
+
+	var generated, delivered, failed atomic.Int64
+	startTime := time.Now()
+	limit := &generateLimit{count: opts.Count}
+	if opts.Duration > 0 {
+		limit.deadline = startTime.Add(opts.Duration)
+	}
+
+	var stopProgress func()
+	if opts.Progress != nil {
+		stop := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(generateProgressInterval)
+			defer ticker.Stop()
+			last, lastTime := int64(0), startTime
+			for {
+				select {
+				case <-stop:
+					return
+				case now := <-ticker.C:
+					progress := GenerateProgress{
+						Generated: generated.Load(),
+						Delivered: delivered.Load(),
+						Failed:    failed.Load(),
+						Elapsed:   now.Sub(startTime),
+					}
+					progress.Rate = float64(progress.Delivered-last) / now.Sub(lastTime).Seconds()
+					last, lastTime = progress.Delivered, now
+					opts.Progress(progress)
+				}
+			}
+		}()
+		stopProgress = func() {
+			close(stop)
+			wg.Wait()
+		}
+	}
+
+	summary, err := produceRecords(ctx, "generator", limit, func(rec *MessageRecord) (*kafka.Message, error) {
+		msg, err := gen.Next()
+		if err != nil {
+			return nil, err
+		}
+		spec := MessageSpec{
+			Topic:       opts.Topic,
+			Value:       []byte(msg.Value),
+			Headers:     opts.Headers,
+			Partition:   opts.Partition,
+			Partitioner: opts.Partitioner,
+		}
+		if msg.Key != nil {
+			spec.Key = []byte(*msg.Key)
+		}
+		kafkaMsg, err := producer.NewMessage(spec)
+		if err == nil {
+			generated.Add(1)
+		}
+		return kafkaMsg, err
+	}, opts.Limits, func(res DeliveryResult) {
+		if res.Error != nil {
+			failed.Add(1)
+		} else {
+			delivered.Add(1)
+		}
+	})
+
+	if stopProgress != nil {
+		stopProgress()
+	}
+	if err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		err = nil
+	}
+	if summary != nil {
+		logger.Info("Generated messages", "topic", opts.Topic, "generated", summary.Read,
+			"delivered", summary.Delivered, "failed", summary.Failed, "elapsed", summary.Elapsed)
+	}
+	return summary, err
+}
+
+// generateLimit reads empty records until count records were read or the
+// deadline has passed, a zero count or deadline does not end reading.
+type generateLimit struct {
+	count    int64
+	read     int64
+	deadline time.Time
+}
+
+func (l *generateLimit) Read() (*MessageRecord, error) {
+	if l.count > 0 && l.read >= l.count {
+		return nil, io.EOF
+	}
+	if !l.deadline.IsZero() && !time.Now().Before(l.deadline) {
+		return nil, io.EOF
+	}
+	l.read++
+	return &MessageRecord{}, nil
+}
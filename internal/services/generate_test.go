@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"kafctl/internal/services/mocks"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Generator(t *testing.T) {
+
+	valueTemplate := `{"id": {{json .Key}}, "n": {{seq}}, "order": {{seq 1000}}, "qty": {{randInt 1 3}},` +
+		` "price": {{printf "%.2f" (randFloat 1 2)}}, "code": "{{randString 6}}", "paid": {{randBool}},` +
+		` "status": "{{pick "NEW" "PAID"}}", "ts": {{unixMs}}}`
+	gen, err := NewGenerator(`{{uuid}}`, valueTemplate, 42)
+	assert.NoError(t, err)
+
+	var first []GeneratedMessage
+	for i := 1; i <= 3; i++ {
+		msg, err := gen.Next()
+		assert.NoError(t, err)
+		first = append(first, msg)
+
+		_, err = uuid.Parse(*msg.Key)
+		assert.NoError(t, err)
+
+		var value struct {
+			ID     string  `json:"id"`
+			N      int     `json:"n"`
+			Order  int     `json:"order"`
+			Qty    int     `json:"qty"`
+			Price  float64 `json:"price"`
+			Code   string  `json:"code"`
+			Status string  `json:"status"`
+			Ts     int64   `json:"ts"`
+		}
+		assert.NoError(t, json.Unmarshal([]byte(msg.Value), &value), msg.Value)
+		assert.Equal(t, *msg.Key, value.ID)
+		assert.Equal(t, i, value.N)
+		assert.Equal(t, 999+i, value.Order)
+		assert.GreaterOrEqual(t, value.Qty, 1)
+		assert.LessOrEqual(t, value.Qty, 3)
+		assert.GreaterOrEqual(t, value.Price, 1.0)
+		assert.LessOrEqual(t, value.Price, 2.0)
+		assert.Len(t, value.Code, 6)
+		assert.Contains(t, []string{"NEW", "PAID"}, value.Status)
+		assert.InDelta(t, time.Now().UnixMilli(), value.Ts, 10000)
+	}
+
+	// the same seed renders the same random values
+	gen, err = NewGenerator(`{{uuid}}`, `{{randString 12}}`, 42)
+	assert.NoError(t, err)
+	msg, err := gen.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, *first[0].Key, *msg.Key)
+
+	gen, err = NewGenerator("", `{{seq}}`, 0)
+	assert.NoError(t, err)
+	msg, err = gen.Next()
+	assert.NoError(t, err)
+	assert.Nil(t, msg.Key)
+	assert.Equal(t, "1", msg.Value)
+
+	_, err = NewGenerator("", "", 0)
+	assert.ErrorContains(t, err, "value template is empty")
+	_, err = NewGenerator("{{uuid", "v", 0)
+	assert.ErrorContains(t, err, "invalid key template")
+	_, err = NewGenerator("", "{{unknown}}", 0)
+	assert.ErrorContains(t, err, "invalid value template")
+
+	gen, err = NewGenerator("", "{{randInt 5 1}}", 0)
+	assert.NoError(t, err)
+	_, err = gen.Next()
+	assert.ErrorContains(t, err, "min 5 is above max 1")
+}
+
+func Test_GenerateMessages(t *testing.T) {
+
+	rdProducer := mocks.NewMockProducer()
+	producer := NewKafProducerFrom(rdProducer)
+	NewKafProducer = func() (IKafProducer, error) { return producer, nil }
+	defer func() { NewKafProducer = CreateKafProducer }()
+
+	rdProducer.On("Produce", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		msg := args.Get(0).(*kafka.Message)
+		n, _ := strconv.Atoi(string(msg.Key))
+		msg.TopicPartition.Partition = int32(n % 2)
+		rdProducer.EventsChan <- msg
+	}).Return(nil)
+
+	summary, err := GenerateMessages(context.Background(), GenerateOptions{
+		Topic:         "orders",
+		KeyTemplate:   "{{seq}}",
+		ValueTemplate: `{"order": {{seq}}}`,
+		Headers:       []kafka.Header{{Key: "source", Value: []byte("generator")}},
+		Partition:     kafka.PartitionAny,
+		Count:         5,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), summary.Delivered)
+	assert.Equal(t, map[string]map[int32]int64{"orders": {0: 2, 1: 3}}, summary.Partitions)
+
+	msg := rdProducer.Calls[4].Arguments.Get(0).(*kafka.Message)
+	assert.Equal(t, "5", string(msg.Key))
+	assert.Equal(t, `{"order": 5}`, string(msg.Value))
+	assert.Equal(t, []kafka.Header{{Key: "source", Value: []byte("generator")}}, msg.Headers)
+
+	// a duration ends the run, the rate spaces the messages out
+	summary, err = GenerateMessages(context.Background(), GenerateOptions{
+		Topic:         "orders",
+		ValueTemplate: "v",
+		Partition:     kafka.PartitionAny,
+		Duration:      200 * time.Millisecond,
+		Limits:        ProduceLimits{Rate: 50},
+	})
+	assert.NoError(t, err)
+	assert.InDelta(t, 10, summary.Delivered, 2)
+
+	// stopping through the context is not an error
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	summary, err = GenerateMessages(ctx, GenerateOptions{Topic: "orders", ValueTemplate: "v", Partition: kafka.PartitionAny})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), summary.Delivered)
+
+	_, err = GenerateMessages(context.Background(), GenerateOptions{
+		Topic: "orders", ValueTemplate: "{{pick}}", Partition: kafka.PartitionAny, Count: 1,
+	})
+	assert.ErrorContains(t, err, "no values to pick from")
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// Encodings of a header value. HeaderNull is only used by ParseHeaderFlag,
+// a null header is a RecordHeader with a nil Value.
+const (
+	HeaderText   = "text"
+	HeaderBase64 = "base64"
+	HeaderHex    = "hex"
+	HeaderNull   = "null"
+)
+
+var HeaderEncodings = []string{HeaderText, HeaderBase64, HeaderHex}
+
+// Bytes decodes the header value. Without an encoding of its own the value
+// is decoded with recordEncoding, the encoding of the whole record.
+func (h RecordHeader) Bytes(recordEncoding string) ([]byte, error) {
+	if h.Value == nil {
+		return nil, nil
+	}
+	encoding := h.Encoding
+	if encoding == "" {
+		encoding = recordEncoding
+	}
+
+	var value []byte
+	var err error
+	switch encoding {
+	case "", HeaderText:
+		value = []byte(*h.Value)
+	case HeaderBase64:
+		value, err = base64.StdEncoding.DecodeString(*h.Value)
+	case HeaderHex:
+		value, err = hex.DecodeString(*h.Value)
+	default:
+		return nil, fmt.Errorf("unknown encoding '%s', expected one of %s", encoding, strings.Join(HeaderEncodings, ", "))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value: %w", encoding, err)
+	}
+	return value, nil
+}
+
+// KafkaHeaders decodes headers given by a user in order, keeping repeated
+// keys. The error names the first invalid header.
+func KafkaHeaders(headers []RecordHeader) ([]kafka.Header, error) {
+	for i, h := range headers {
+		if h.Key == "" {
+			return nil, fmt.Errorf("header %d: the key is empty", i+1)
+		}
+	}
+	return kafkaHeaders(headers, "")
+}
+
+func kafkaHeaders(headers []RecordHeader, recordEncoding string) ([]kafka.Header, error) {
+	res := make([]kafka.Header, 0, len(headers))
+	for i, h := range headers {
+		value, err := h.Bytes(recordEncoding)
+		if err != nil {
+			return nil, fmt.Errorf("header %d (%s): %w", i+1, h.Key, err)
+		}
+		res = append(res, kafka.Header{Key: h.Key, Value: value})
+	}
+	return res, nil
+}
+
+// ParseHeaderFlag parses a header given as key=value. A key ending in
+// :base64, :hex or :text sets the encoding of the value and key:null is a
+// header without value. Any other colon is part of the key.
+func ParseHeaderFlag(s string) (RecordHeader, error) {
+	if key, ok := strings.CutSuffix(s, ":"+HeaderNull); ok && !strings.Contains(key, "=") {
+		if key == "" {
+			return RecordHeader{}, fmt.Errorf("invalid header '%s', the key is empty", s)
+		}
+		return RecordHeader{Key: key}, nil
+	}
+
+	key, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return RecordHeader{}, fmt.Errorf("invalid header '%s', expected key=value or key:null", s)
+	}
+	h := RecordHeader{Key: key, Value: &value}
+	if i := strings.LastIndex(key, ":"); i >= 0 {
+		switch key[i+1:] {
+		case HeaderText, HeaderBase64, HeaderHex:
+			h.Key, h.Encoding = key[:i], key[i+1:]
+		}
+	}
+	if h.Key == "" {
+		return RecordHeader{}, fmt.Errorf("invalid header '%s', the key is empty", s)
+	}
+	if _, err := h.Bytes(""); err != nil {
+		return RecordHeader{}, fmt.Errorf("invalid header '%s': %w", s, err)
+	}
+	return h, nil
+}
+
+// ReadHeadersFile reads a JSON array of headers such as
+// [{"key": "traceId", "value": "abc"}, {"key": "sig", "value": "AAEC", "encoding": "base64"}]
+// where a null value is a header without value.
+func ReadHeadersFile(path string) ([]RecordHeader, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read headers file: %w", err)
+	}
+	var headers []RecordHeader
+	if err := json.Unmarshal(data, &headers); err != nil {
+		return nil, fmt.Errorf("invalid headers file %s: %w", path, err)
+	}
+	if _, err := KafkaHeaders(headers); err != nil {
+		return nil, fmt.Errorf("invalid headers file %s: %w", path, err)
+	}
+	return headers, nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseHeaderFlag(t *testing.T) {
+
+	value := func(s string) *string { return &s }
+
+	tests := []struct {
+		flag string
+		want RecordHeader
+	}{
+		{"traceId=a,b=c", RecordHeader{Key: "traceId", Value: value("a,b=c")}},
+		{"empty=", RecordHeader{Key: "empty", Value: value("")}},
+		{"sig:base64=AAEC", RecordHeader{Key: "sig", Value: value("AAEC"), Encoding: HeaderBase64}},
+		{"sig:hex=00ff", RecordHeader{Key: "sig", Value: value("00ff"), Encoding: HeaderHex}},
+		{"ns:key=v", RecordHeader{Key: "ns:key", Value: value("v")}},
+		{"x:hex:text=v", RecordHeader{Key: "x:hex", Value: value("v"), Encoding: HeaderText}},
+		{"tombstone:null", RecordHeader{Key: "tombstone"}},
+	}
+	for _, tt := range tests {
+		h, err := ParseHeaderFlag(tt.flag)
+		assert.NoError(t, err, tt.flag)
+		assert.Equal(t, tt.want, h, tt.flag)
+	}
+
+	for _, flag := range []string{"traceId", "=v", ":null", "sig:base64=!!", "sig:hex=0"} {
+		_, err := ParseHeaderFlag(flag)
+		assert.Error(t, err, flag)
+	}
+}
+
+func Test_KafkaHeaders(t *testing.T) {
+
+	value := func(s string) *string { return &s }
+
+	headers, err := KafkaHeaders([]RecordHeader{
+		{Key: "k", Value: value("1")},
+		{Key: "k", Value: value("AAE="), Encoding: HeaderBase64},
+		{Key: "k"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []kafka.Header{{Key: "k", Value: []byte("1")}, {Key: "k", Value: []byte{0, 1}}, {Key: "k"}}, headers)
+
+	_, err = KafkaHeaders([]RecordHeader{{Key: "k", Value: value("1")}, {Key: "", Value: value("2")}})
+	assert.ErrorContains(t, err, "header 2: the key is empty")
+
+	_, err = KafkaHeaders([]RecordHeader{{Key: "k", Value: value("1"), Encoding: "utf16"}})
+	assert.ErrorContains(t, err, "unknown encoding 'utf16'")
+
+	// a header encoding wins over the record encoding
+	rec := MessageRecord{Encoding: EncodingBase64, Headers: []RecordHeader{
+		{Key: "a", Value: value("AAE=")},
+		{Key: "b", Value: value("plain"), Encoding: HeaderText},
+	}}
+	headers, err = rec.KafkaHeaders()
+	assert.NoError(t, err)
+	assert.Equal(t, []kafka.Header{{Key: "a", Value: []byte{0, 1}}, {Key: "b", Value: []byte("plain")}}, headers)
+}
+
+func Test_ReadHeadersFile(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "headers.json")
+	err := os.WriteFile(path, []byte(`[{"key": "traceId", "value": "abc"}, {"key": "sig", "value": "00ff", "encoding": "hex"}, {"key": "gone", "value": null}]`), 0600)
+	assert.NoError(t, err)
+
+	headers, err := ReadHeadersFile(path)
+	assert.NoError(t, err)
+	assert.Len(t, headers, 3)
+	assert.Nil(t, headers[2].Value)
+
+	err = os.WriteFile(path, []byte(`[{"key": "sig", "value": "zz", "encoding": "hex"}]`), 0600)
+	assert.NoError(t, err)
+	_, err = ReadHeadersFile(path)
+	assert.ErrorContains(t, err, "header 1 (sig): invalid hex value")
+}
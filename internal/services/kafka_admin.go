@@ -2,24 +2,50 @@ package services
 
 import (
 	"encoding/json"
+	"fmt"
 	"kafctl/internal/config"
 	"kafctl/internal/logger"
 	"os"
+	"strings"
 
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 	"github.com/google/uuid"
 )
 
+// SSLConfig is the security config file given with -f. Despite its name it
+// also holds the SASL settings used with SASL_PLAINTEXT and SASL_SSL.
 type SSLConfig struct {
 	SecurityProtocol string `json:"securityProtocol"`
 	SslCaLocation    string `json:"sslCaLocation"`
 	SslCertLocation  string `json:"sslCertLocation"`
 	SslKeyLocation   string `json:"sslKeyLocation"`
 	SslKeyPassword   string `json:"sslKeyPassword"`
+
+	// PLAIN, SCRAM-SHA-256, SCRAM-SHA-512 or OAUTHBEARER
+	SaslMechanism string `json:"saslMechanism"`
+	SaslUsername  string `json:"saslUsername"`
+	SaslPassword  string `json:"saslPassword"`
+
+	// OAUTHBEARER tokens are fetched from the token endpoint with the client
+	// credentials grant.
+	OAuthTokenEndpoint string `json:"oauthTokenEndpoint"`
+	OAuthClientId      string `json:"oauthClientId"`
+	OAuthClientSecret  string `json:"oauthClientSecret"`
+	OAuthScope         string `json:"oauthScope"`
+	OAuthExtensions    string `json:"oauthExtensions"`
 }
 
+// SASL mechanisms supported in the security config
+const (
+	SaslPlain       = "PLAIN"
+	SaslScramSha256 = "SCRAM-SHA-256"
+	SaslScramSha512 = "SCRAM-SHA-512"
+	SaslOAuthBearer = "OAUTHBEARER"
+)
+
 var NewAdminClient = kafka.NewAdminClient
 
+// SetSSLConfig reads the security config file and applies it to cfgMap.
 func SetSSLConfig(cfgMap *kafka.ConfigMap, sslConfigFile string) error {
 	// Read SSL configuration from file
 	sslCfgFile, err := os.Open(sslConfigFile)
@@ -32,14 +58,61 @@ func SetSSLConfig(cfgMap *kafka.ConfigMap, sslConfigFile string) error {
 	if err := json.NewDecoder(sslCfgFile).Decode(&sslConfig); err != nil {
 		return err
 	}
+	return sslConfig.Apply(cfgMap)
+}
+
+// Apply sets security.protocol with the SSL and SASL keys it needs. Empty SSL
+// paths are left to the librdkafka defaults, e.g. the system CA store.
+func (c SSLConfig) Apply(cfgMap *kafka.ConfigMap) error {
+
+	protocol := strings.ToUpper(c.SecurityProtocol)
+	cfgMap.SetKey("security.protocol", protocol)
+	if protocol == "SSL" || protocol == "SASL_SSL" {
+		for key, value := range map[string]string{
+			"ssl.ca.location":          c.SslCaLocation,
+			"ssl.certificate.location": c.SslCertLocation,
+			"ssl.key.location":         c.SslKeyLocation,
+			"ssl.key.password":         c.SslKeyPassword,
+		} {
+			if value != "" {
+				cfgMap.SetKey(key, value)
+			}
+		}
+	}
+	if protocol != "SASL_SSL" && protocol != "SASL_PLAINTEXT" {
+		return nil
+	}
 
-	cfgMap.SetKey("security.protocol", sslConfig.SecurityProtocol)
-	if sslConfig.SecurityProtocol == "SSL" || sslConfig.SecurityProtocol == "SASL_SSL" {
-		cfgMap.SetKey("ssl.ca.location", sslConfig.SslCaLocation)
-		cfgMap.SetKey("ssl.certificate.location", sslConfig.SslCertLocation)
-		cfgMap.SetKey("ssl.key.location", sslConfig.SslKeyLocation)
-		cfgMap.SetKey("ssl.key.password", sslConfig.SslKeyPassword)
+	mechanism := strings.ToUpper(c.SaslMechanism)
+	switch mechanism {
+	case SaslPlain, SaslScramSha256, SaslScramSha512:
+		if c.SaslUsername == "" || c.SaslPassword == "" {
+			return fmt.Errorf("sasl mechanism %s needs saslUsername and saslPassword", mechanism)
+		}
+		cfgMap.SetKey("sasl.username", c.SaslUsername)
+		cfgMap.SetKey("sasl.password", c.SaslPassword)
+	case SaslOAuthBearer:
+		if c.OAuthTokenEndpoint == "" || c.OAuthClientId == "" {
+			return fmt.Errorf("sasl mechanism %s needs oauthTokenEndpoint and oauthClientId", mechanism)
+		}
+		cfgMap.SetKey("sasl.oauthbearer.method", "oidc")
+		cfgMap.SetKey("sasl.oauthbearer.token.endpoint.url", c.OAuthTokenEndpoint)
+		cfgMap.SetKey("sasl.oauthbearer.client.id", c.OAuthClientId)
+		cfgMap.SetKey("sasl.oauthbearer.client.secret", c.OAuthClientSecret)
+		if c.OAuthScope != "" {
+			cfgMap.SetKey("sasl.oauthbearer.scope", c.OAuthScope)
+		}
+		if c.OAuthExtensions != "" {
+			cfgMap.SetKey("sasl.oauthbearer.extensions", c.OAuthExtensions)
+		}
+	case "":
+		return fmt.Errorf("security protocol %s needs a saslMechanism", protocol)
+	default:
+		return fmt.Errorf("unknown sasl mechanism '%s', expected one of %s, %s, %s, %s",
+			c.SaslMechanism, SaslPlain, SaslScramSha256, SaslScramSha512, SaslOAuthBearer)
 	}
+	cfgMap.SetKey("sasl.mechanism", mechanism)
+	logger.Debug("Applied SASL config", "protocol", protocol, "mechanism", mechanism)
 	return nil
 }
 
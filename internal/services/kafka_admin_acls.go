@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kafctl/internal/logger"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// Timeout for ACL admin requests
+const aclRequestTimeout = 30 * time.Second
+
+// Values accepted for the fields of an ACLSpec, "any" only in filters
+var (
+	ACLResourceTypes   = []string{"topic", "group", "broker"}
+	ACLPatternTypes    = []string{"literal", "prefixed", "match"}
+	ACLOperations      = []string{"all", "read", "write", "create", "delete", "alter", "describe", "cluster_action", "describe_configs", "alter_configs", "idempotent_write"}
+	ACLPermissionTypes = []string{"allow", "deny"}
+)
+
+// ACLSpec holds the fields of an ACL binding as entered on the command line
+// or in a form. In a filter empty fields match anything; "match" as pattern
+// type also finds the prefixed and wildcard bindings that apply to Name.
+type ACLSpec struct {
+	ResourceType string
+	Name         string
+	PatternType  string
+	Principal    string
+	Host         string
+	Operation    string
+	Permission   string
+}
+
+// Filter converts the spec to a filter for DescribeACLs and DeleteACLs.
+func (s ACLSpec) Filter() (kafka.ACLBindingFilter, error) {
+	f := kafka.ACLBindingFilter{Name: s.Name, Principal: s.Principal, Host: s.Host}
+	var err error
+	if f.Type, err = kafka.ResourceTypeFromString(orAny(s.ResourceType)); err != nil {
+		return f, fmt.Errorf("unknown resource type '%s', expected one of %s", s.ResourceType, strings.Join(ACLResourceTypes, ", "))
+	}
+	if f.ResourcePatternType, err = kafka.ResourcePatternTypeFromString(orAny(s.PatternType)); err != nil {
+		return f, fmt.Errorf("unknown pattern type '%s', expected one of %s", s.PatternType, strings.Join(ACLPatternTypes, ", "))
+	}
+	if f.Operation, err = kafka.ACLOperationFromString(orAny(s.Operation)); err != nil {
+		return f, fmt.Errorf("unknown operation '%s', expected one of %s", s.Operation, strings.Join(ACLOperations, ", "))
+	}
+	if f.PermissionType, err = kafka.ACLPermissionTypeFromString(orAny(s.Permission)); err != nil {
+		return f, fmt.Errorf("unknown permission '%s', expected one of %s", s.Permission, strings.Join(ACLPermissionTypes, ", "))
+	}
+	if s.Principal != "" && !strings.Contains(s.Principal, ":") {
+		return f, fmt.Errorf("invalid principal '%s', expected type:name such as User:alice", s.Principal)
+	}
+	return f, nil
+}
+
+// Binding converts the spec to a binding for CreateACLs. Pattern type
+// defaults to literal, host to * and permission to allow; every other field
+// is required.
+func (s ACLSpec) Binding() (kafka.ACLBinding, error) {
+	if s.PatternType == "" {
+		s.PatternType = "literal"
+	}
+	if s.Host == "" {
+		s.Host = "*"
+	}
+	if s.Permission == "" {
+		s.Permission = "allow"
+	}
+	switch {
+	case s.ResourceType == "" || s.Name == "":
+		return kafka.ACLBinding{}, errors.New("resource type and name are required")
+	case s.Principal == "":
+		return kafka.ACLBinding{}, errors.New("principal is required")
+	case s.Operation == "":
+		return kafka.ACLBinding{}, errors.New("operation is required")
+	case strings.EqualFold(s.PatternType, "match"):
+		return kafka.ACLBinding{}, errors.New("pattern type match is only valid in filters")
+	}
+	for _, v := range []string{s.ResourceType, s.PatternType, s.Operation, s.Permission} {
+		if strings.EqualFold(v, "any") {
+			return kafka.ACLBinding{}, errors.New("any is only valid in filters")
+		}
+	}
+	return s.Filter()
+}
+
+func orAny(s string) string {
+	if s == "" {
+		return "any"
+	}
+	return s
+}
+
+// CreateACLs creates the bindings in one request.
+func (ka *KafAdmin) CreateACLs(acls []kafka.ACLBinding) error {
+
+	logger.Info("Creating ACLs", "acls", len(acls))
+	if len(acls) == 0 {
+		return errors.New("no ACLs given")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), aclRequestTimeout)
+	defer cancel()
+
+	results, err := ka.admin.CreateACLs(ctx, acls, kafka.SetAdminRequestTimeout(aclRequestTimeout))
+	if err != nil {
+		logger.Error("Failed to create ACLs", "error", err)
+		return err
+	}
+	var errs []error
+	for i, r := range results {
+		if r.Error.Code() != kafka.ErrNoError && i < len(acls) {
+			errs = append(errs, fmt.Errorf("%s: %w", FormatACL(acls[i]), r.Error))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to create ACLs: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// DescribeACLs returns the bindings matching the filter, sorted by resource
+// and principal.
+func (ka *KafAdmin) DescribeACLs(filter kafka.ACLBindingFilter) ([]kafka.ACLBinding, error) {
+
+	logger.Info("Describing ACLs", "filter", FormatACL(filter))
+
+	ctx, cancel := context.WithTimeout(context.Background(), aclRequestTimeout)
+	defer cancel()
+
+	result, err := ka.admin.DescribeACLs(ctx, filter, kafka.SetAdminRequestTimeout(aclRequestTimeout))
+	if err != nil {
+		logger.Error("Failed to describe ACLs", "error", err)
+		return nil, err
+	}
+	if result.Error.Code() != kafka.ErrNoError {
+		return nil, fmt.Errorf("failed to describe ACLs: %w", result.Error)
+	}
+	acls := result.ACLBindings
+	sort.Sort(acls)
+	return acls, nil
+}
+
+// DeleteACLs deletes every binding matching the filter and returns them.
+func (ka *KafAdmin) DeleteACLs(filter kafka.ACLBindingFilter) ([]kafka.ACLBinding, error) {
+
+	logger.Info("Deleting ACLs", "filter", FormatACL(filter))
+
+	ctx, cancel := context.WithTimeout(context.Background(), aclRequestTimeout)
+	defer cancel()
+
+	results, err := ka.admin.DeleteACLs(ctx, kafka.ACLBindingFilters{filter}, kafka.SetAdminRequestTimeout(aclRequestTimeout))
+	if err != nil {
+		logger.Error("Failed to delete ACLs", "error", err)
+		return nil, err
+	}
+	var deleted kafka.ACLBindings
+	for _, r := range results {
+		if r.Error.Code() != kafka.ErrNoError {
+			return nil, fmt.Errorf("failed to delete ACLs: %w", r.Error)
+		}
+		deleted = append(deleted, r.ACLBindings...)
+	}
+	sort.Sort(deleted)
+	logger.Info("Deleted ACLs", "acls", len(deleted))
+	return deleted, nil
+}
+
+// FormatACL prints a binding or filter in the order Kafka's own tools use.
+func FormatACL(acl kafka.ACLBinding) string {
+	return fmt.Sprintf("%s %s from host %s %s %s:%s (%s)",
+		acl.Principal, acl.PermissionType, acl.Host, acl.Operation, acl.Type, acl.Name, acl.ResourcePatternType)
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_ACLSpec(t *testing.T) {
+
+	filter, err := ACLSpec{Principal: "User:billing", ResourceType: "topic"}.Filter()
+	assert.NoError(t, err)
+	assert.Equal(t, kafka.ACLBindingFilter{
+		Type:                kafka.ResourceTopic,
+		ResourcePatternType: kafka.ResourcePatternTypeAny,
+		Principal:           "User:billing",
+		Operation:           kafka.ACLOperationAny,
+		PermissionType:      kafka.ACLPermissionTypeAny,
+	}, filter)
+
+	binding, err := ACLSpec{ResourceType: "topic", Name: "orders-", PatternType: "prefixed", Principal: "User:billing", Operation: "read"}.Binding()
+	assert.NoError(t, err)
+	assert.Equal(t, kafka.ACLBinding{
+		Type:                kafka.ResourceTopic,
+		Name:                "orders-",
+		ResourcePatternType: kafka.ResourcePatternTypePrefixed,
+		Principal:           "User:billing",
+		Host:                "*",
+		Operation:           kafka.ACLOperationRead,
+		PermissionType:      kafka.ACLPermissionTypeAllow,
+	}, binding)
+
+	for _, spec := range []ACLSpec{
+		{ResourceType: "topic", Name: "orders", Principal: "User:billing"},
+		{ResourceType: "topic", Name: "orders", Principal: "billing", Operation: "read"},
+		{ResourceType: "topic", Name: "orders", Principal: "User:billing", Operation: "any"},
+		{ResourceType: "topic", Name: "orders", PatternType: "match", Principal: "User:billing", Operation: "read"},
+		{ResourceType: "queue", Name: "orders", Principal: "User:billing", Operation: "read"},
+	} {
+		_, err := spec.Binding()
+		assert.Error(t, err, "%+v", spec)
+	}
+}
+
+func Test_CreateACLs(t *testing.T) {
+
+	kafAdmin, mockAdmin, resetAdmin, err := setup(t)
+	assert.NoError(t, err)
+	defer resetAdmin()
+
+	acls := []kafka.ACLBinding{
+		{Type: kafka.ResourceTopic, Name: "orders", ResourcePatternType: kafka.ResourcePatternTypeLiteral, Principal: "User:billing",
+			Host: "*", Operation: kafka.ACLOperationRead, PermissionType: kafka.ACLPermissionTypeAllow},
+		{Type: kafka.ResourceGroup, Name: "billing", ResourcePatternType: kafka.ResourcePatternTypeLiteral, Principal: "User:billing",
+			Host: "*", Operation: kafka.ACLOperationRead, PermissionType: kafka.ACLPermissionTypeAllow},
+	}
+	mockAdmin.On("CreateACLs", mock.Anything, kafka.ACLBindings(acls), mock.Anything).
+		Return([]kafka.CreateACLResult{{}, {Error: kafka.NewError(kafka.ErrSecurityDisabled, "no authorizer", false)}}, nil)
+
+	err = kafAdmin.CreateACLs(acls)
+	assert.ErrorContains(t, err, "no authorizer")
+	assert.ErrorContains(t, err, "billing")
+
+	assert.Error(t, kafAdmin.CreateACLs(nil))
+}
+
+func Test_DescribeAndDeleteACLs(t *testing.T) {
+
+	kafAdmin, mockAdmin, resetAdmin, err := setup(t)
+	assert.NoError(t, err)
+	defer resetAdmin()
+
+	read := kafka.ACLBinding{Type: kafka.ResourceTopic, Name: "orders", Principal: "User:billing", Operation: kafka.ACLOperationRead}
+	write := kafka.ACLBinding{Type: kafka.ResourceTopic, Name: "invoices", Principal: "User:billing", Operation: kafka.ACLOperationWrite}
+	filter := kafka.ACLBindingFilter{Principal: "User:billing"}
+
+	mockAdmin.On("DescribeACLs", mock.Anything, filter, mock.Anything).
+		Return(&kafka.DescribeACLsResult{ACLBindings: kafka.ACLBindings{read, write}}, nil)
+	acls, err := kafAdmin.DescribeACLs(filter)
+	assert.NoError(t, err)
+	assert.Equal(t, []kafka.ACLBinding{write, read}, acls)
+
+	mockAdmin.On("DeleteACLs", mock.Anything, kafka.ACLBindingFilters{filter}, mock.Anything).
+		Return([]kafka.DeleteACLsResult{{ACLBindings: kafka.ACLBindings{read, write}}}, nil)
+	deleted, err := kafAdmin.DeleteACLs(filter)
+	assert.NoError(t, err)
+	assert.Equal(t, []kafka.ACLBinding{write, read}, deleted)
+}
@@ -13,9 +13,33 @@ type IKafAdmin interface {
 	GetClusterDetails() ([]kafka.BrokerMetadata, error)
 	GetAllTopics() (map[string]kafka.TopicMetadata, error)
 	CreateTopic(topic string, numParts, replicationFactor int) error
+	CreateTopicWithConfig(spec TopicSpec) error
 	DeleteTopic(topic string) error
+	CreatePartitions(topic string, increaseTo int, validateOnly bool) error
 	DescribeTopic(topic string) (kafka.DescribeTopicsResult, error)
 	GetListOffsets(topicName string, partition int) error
+	ListConsumerGroups() ([]kafka.ConsumerGroupListing, error)
+	DescribeConsumerGroups(groups []string) ([]kafka.ConsumerGroupDescription, error)
+	ListConsumerGroupOffsets(group string) ([]kafka.TopicPartition, error)
+	ListOffsets(partitions []kafka.TopicPartition, spec kafka.OffsetSpec) ([]kafka.TopicPartition, error)
+	AlterConsumerGroupOffsets(group string, offsets []kafka.TopicPartition) ([]kafka.TopicPartition, error)
+	PlanOffsetReset(group, topic string, spec OffsetResetSpec) ([]OffsetReset, error)
+	ResetConsumerGroupOffsets(group string, plan []OffsetReset) error
+	SelectConsumerGroups(selector GroupSelector) ([]string, error)
+	DeleteConsumerGroups(groups []string) ([]kafka.ConsumerGroupResult, error)
+	DescribeTopicConfig(topic string) ([]ConfigValue, error)
+	AlterTopicConfig(topic string, changes []ConfigChange) error
+	DescribeCluster() (kafka.DescribeClusterResult, error)
+	DescribeBrokerConfig(broker int) ([]ConfigValue, error)
+	CreateACLs(acls []kafka.ACLBinding) error
+	DescribeACLs(filter kafka.ACLBindingFilter) ([]kafka.ACLBinding, error)
+	DeleteACLs(filter kafka.ACLBindingFilter) ([]kafka.ACLBinding, error)
+	DescribeUserScramCredentials(users []string) ([]kafka.UserScramCredentialsDescription, error)
+	UpsertUserScramCredential(user, mechanism string, iterations int, password []byte) error
+	DeleteUserScramCredential(user, mechanism string) error
+	DeleteRecords(offsets []kafka.TopicPartition) ([]kafka.TopicPartition, error)
+	PlanPurge(topic string, spec PurgeSpec) ([]RecordPurge, error)
+	PurgeRecords(plan []RecordPurge) error
 	Close()
 }
 
@@ -78,6 +102,12 @@ func (ka *KafAdmin) GetAllTopics() (map[string]kafka.TopicMetadata, error) {
 }
 
 func (ka *KafAdmin) CreateTopic(topic string, numParts, replicationFactor int) error {
+	return ka.CreateTopicWithConfig(TopicSpec{Name: topic, Partitions: numParts, ReplicationFactor: replicationFactor})
+}
+
+// CreateTopicWithConfig creates a topic with the config entries of the spec.
+// With ValidateOnly the broker only checks the request and nothing is created.
+func (ka *KafAdmin) CreateTopicWithConfig(spec TopicSpec) error {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -91,11 +121,13 @@ func (ka *KafAdmin) CreateTopic(topic string, numParts, replicationFactor int) e
 		// Multiple topics can be created simultaneously
 		// by providing more TopicSpecification structs here.
 		[]kafka.TopicSpecification{{
-			Topic:             topic,
-			NumPartitions:     numParts,
-			ReplicationFactor: replicationFactor}},
+			Topic:             spec.Name,
+			NumPartitions:     spec.Partitions,
+			ReplicationFactor: spec.ReplicationFactor,
+			Config:            spec.Config}},
 		// Admin options
-		kafka.SetAdminOperationTimeout(maxDur))
+		kafka.SetAdminOperationTimeout(maxDur),
+		kafka.SetAdminValidateOnly(spec.ValidateOnly))
 	if err != nil {
 		logger.Error("Failed to create topic: ", "error", err)
 		return err
@@ -108,7 +140,11 @@ func (ka *KafAdmin) CreateTopic(topic string, numParts, replicationFactor int) e
 			logger.Error("Failed to create topic", "topic", result.Topic, "error", errMsg)
 			return fmt.Errorf("failed to create topic '%s': %s", result.Topic, result.Error.String())
 		}
-		logger.Info("Topic created successfully", "topic", result.Topic)
+		if spec.ValidateOnly {
+			logger.Info("Topic validated", "topic", result.Topic, "config", spec.Config)
+			continue
+		}
+		logger.Info("Topic created successfully", "topic", result.Topic, "config", spec.Config)
 	}
 	return nil
 }
@@ -136,6 +172,40 @@ func (ka *KafAdmin) DeleteTopic(topic string) error {
 	return nil
 }
 
+// CreatePartitions raises the partition count of the topic to increaseTo.
+// Partitions can only be added, and keys hash to different partitions
+// afterwards. With validateOnly the broker only checks the request.
+func (ka *KafAdmin) CreatePartitions(topic string, increaseTo int, validateOnly bool) error {
+
+	partitions, err := ka.topicPartitions(topic)
+	if err != nil {
+		return err
+	}
+	if increaseTo <= len(partitions) {
+		return fmt.Errorf("topic '%s' already has %d partitions, the new count must be higher", topic, len(partitions))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	results, err := ka.admin.CreatePartitions(ctx,
+		[]kafka.PartitionsSpecification{{Topic: topic, IncreaseTo: increaseTo}},
+		kafka.SetAdminOperationTimeout(time.Second*30),
+		kafka.SetAdminValidateOnly(validateOnly))
+	if err != nil {
+		logger.Error("Failed to create partitions", "topic", topic, "error", err)
+		return err
+	}
+	for _, result := range results {
+		if result.Error.Code() != kafka.ErrNoError {
+			logger.Error("Failed to create partitions", "topic", result.Topic, "error", result.Error)
+			return fmt.Errorf("failed to create partitions for topic '%s': %s", result.Topic, result.Error.String())
+		}
+	}
+	logger.Info("Partitions created", "topic", topic, "from", len(partitions), "to", increaseTo, "validateOnly", validateOnly)
+	return nil
+}
+
 func (ka *KafAdmin) DescribeTopic(topic string) (kafka.DescribeTopicsResult, error) {
 
 	logger.Info("getting details for topic - ", "topic", topic)
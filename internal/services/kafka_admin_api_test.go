@@ -146,3 +146,31 @@ func Test_ListOffsets(t *testing.T) {
 	err = kafAdmin.GetListOffsets(topic, partition)
 	assert.NoError(t, err)
 }
+
+func Test_CreatePartitions(t *testing.T) {
+
+	kafAdmin, mockAdmin, resetAdmin, err := setup(t)
+	assert.NoError(t, err)
+	defer resetAdmin()
+
+	desc := kafka.DescribeTopicsResult{TopicDescriptions: []kafka.TopicDescription{{
+		Name:       "orders",
+		Partitions: []kafka.TopicPartitionInfo{{Partition: 0}, {Partition: 1}, {Partition: 2}},
+	}}}
+	mockAdmin.On("DescribeTopics", mock.Anything, mock.Anything, mock.Anything).Return(desc, nil)
+
+	err = kafAdmin.CreatePartitions("orders", 3, false)
+	assert.ErrorContains(t, err, "already has 3 partitions")
+	mockAdmin.AssertNotCalled(t, "CreatePartitions", mock.Anything, mock.Anything, mock.Anything)
+
+	expected := []kafka.PartitionsSpecification{{Topic: "orders", IncreaseTo: 6}}
+	mockAdmin.On("CreatePartitions", mock.Anything, expected, mock.Anything).
+		Return([]kafka.TopicResult{{Topic: "orders"}}, nil).Once()
+	err = kafAdmin.CreatePartitions("orders", 6, false)
+	assert.NoError(t, err)
+
+	mockAdmin.On("CreatePartitions", mock.Anything, mock.Anything, mock.Anything).
+		Return([]kafka.TopicResult{{Topic: "orders", Error: kafka.NewError(kafka.ErrInvalidPartitions, "not enough brokers", false)}}, nil)
+	err = kafAdmin.CreatePartitions("orders", 12, true)
+	assert.ErrorContains(t, err, "not enough brokers")
+}
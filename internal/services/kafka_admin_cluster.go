@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"kafctl/internal/logger"
+	"sort"
+	"strconv"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ConfigDiff holds the value of one config on every compared broker, in the
+// order of the broker ids passed to DiffBrokerConfigs. Values of brokers
+// without the config are empty.
+type ConfigDiff struct {
+	Name    string
+	Values  []string
+	Differs bool
+}
+
+// DescribeCluster returns the cluster id, the controller and the brokers of
+// the cluster. Brokers are sorted by id.
+func (ka *KafAdmin) DescribeCluster() (kafka.DescribeClusterResult, error) {
+
+	logger.Info("Describing cluster")
+
+	ctx, cancel := context.WithTimeout(context.Background(), configRequestTimeout)
+	defer cancel()
+
+	result, err := ka.admin.DescribeCluster(ctx, kafka.SetAdminRequestTimeout(configRequestTimeout))
+	if err != nil {
+		logger.Error("Failed to describe cluster", "error", err)
+		return kafka.DescribeClusterResult{}, err
+	}
+	sort.Slice(result.Nodes, func(i, j int) bool { return result.Nodes[i].ID < result.Nodes[j].ID })
+	return result, nil
+}
+
+// DescribeBrokerConfig returns every config of the broker sorted by name.
+func (ka *KafAdmin) DescribeBrokerConfig(broker int) ([]ConfigValue, error) {
+	return ka.describeConfigs(kafka.ResourceBroker, strconv.Itoa(broker))
+}
+
+// DescribeBrokerConfigs describes the configs of every broker in brokers,
+// keyed by broker id.
+func DescribeBrokerConfigs(admin IKafAdmin, brokers []int) (map[int][]ConfigValue, error) {
+	configs := make(map[int][]ConfigValue, len(brokers))
+	for _, id := range brokers {
+		c, err := admin.DescribeBrokerConfig(id)
+		if err != nil {
+			return nil, fmt.Errorf("broker %d: %w", id, err)
+		}
+		configs[id] = c
+	}
+	return configs, nil
+}
+
+// DiffBrokerConfigs lines up the configs of the brokers by name. With all
+// false only configs whose value is not the same on every broker are kept.
+// Sensitive values are never compared since brokers do not return them.
+func DiffBrokerConfigs(brokers []int, configs map[int][]ConfigValue, all bool) []ConfigDiff {
+
+	values := make(map[string][]string)
+	sensitive := make(map[string]bool)
+	for i, id := range brokers {
+		for _, c := range configs[id] {
+			if values[c.Name] == nil {
+				values[c.Name] = make([]string, len(brokers))
+			}
+			values[c.Name][i] = c.Value
+			sensitive[c.Name] = sensitive[c.Name] || c.Sensitive
+		}
+	}
+
+	diffs := make([]ConfigDiff, 0, len(values))
+	for name, vals := range values {
+		differs := false
+		for _, v := range vals[1:] {
+			if v != vals[0] && !sensitive[name] {
+				differs = true
+				break
+			}
+		}
+		if differs || all {
+			diffs = append(diffs, ConfigDiff{Name: name, Values: vals, Differs: differs})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Name < diffs[j].Name })
+	return diffs
+}
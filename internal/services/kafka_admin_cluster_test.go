@@ -0,0 +1,64 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_DescribeCluster(t *testing.T) {
+
+	kafAdmin, mockAdmin, resetAdmin, err := setup(t)
+	assert.NoError(t, err)
+	defer resetAdmin()
+
+	clusterID := "lkc-123"
+	mockAdmin.On("DescribeCluster", mock.Anything, mock.Anything).Return(kafka.DescribeClusterResult{
+		ClusterID:  &clusterID,
+		Controller: &kafka.Node{ID: 2, Host: "b2", Port: 9092},
+		Nodes:      []kafka.Node{{ID: 3, Host: "b3"}, {ID: 1, Host: "b1"}, {ID: 2, Host: "b2"}},
+	}, nil)
+
+	cluster, err := kafAdmin.DescribeCluster()
+	assert.NoError(t, err)
+	assert.Equal(t, "lkc-123", *cluster.ClusterID)
+	assert.Equal(t, 2, cluster.Controller.ID)
+	assert.Equal(t, []kafka.Node{{ID: 1, Host: "b1"}, {ID: 2, Host: "b2"}, {ID: 3, Host: "b3"}}, cluster.Nodes)
+}
+
+func Test_DescribeBrokerConfig(t *testing.T) {
+
+	kafAdmin, mockAdmin, resetAdmin, err := setup(t)
+	assert.NoError(t, err)
+	defer resetAdmin()
+
+	mockAdmin.On("DescribeConfigs", mock.Anything, []kafka.ConfigResource{{Type: kafka.ResourceBroker, Name: "2"}}, mock.Anything).
+		Return([]kafka.ConfigResourceResult{{Type: kafka.ResourceBroker, Name: "2", Config: map[string]kafka.ConfigEntryResult{
+			"log.retention.hours": {Name: "log.retention.hours", Value: "72", Source: kafka.ConfigSourceStaticBroker},
+		}}}, nil)
+
+	configs, err := kafAdmin.DescribeBrokerConfig(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []ConfigValue{{Name: "log.retention.hours", Value: "72", Source: "static broker"}}, configs)
+}
+
+func Test_DiffBrokerConfigs(t *testing.T) {
+
+	configs := map[int][]ConfigValue{
+		1: {{Name: "num.io.threads", Value: "8"}, {Name: "log.retention.hours", Value: "168"}, {Name: "ssl.key.password", Sensitive: true}},
+		2: {{Name: "num.io.threads", Value: "8"}, {Name: "log.retention.hours", Value: "72"}, {Name: "ssl.key.password", Sensitive: true}},
+		3: {{Name: "num.io.threads", Value: "8"}},
+	}
+
+	diffs := DiffBrokerConfigs([]int{1, 2, 3}, configs, false)
+	assert.Equal(t, []ConfigDiff{
+		{Name: "log.retention.hours", Values: []string{"168", "72", ""}, Differs: true},
+	}, diffs)
+
+	diffs = DiffBrokerConfigs([]int{1, 2, 3}, configs, true)
+	assert.Len(t, diffs, 3)
+	assert.Equal(t, ConfigDiff{Name: "num.io.threads", Values: []string{"8", "8", "8"}}, diffs[1])
+	assert.False(t, diffs[2].Differs)
+}
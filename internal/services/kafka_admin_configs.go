@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kafctl/internal/logger"
+	"maps"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// Timeout for describing and altering configs
+const configRequestTimeout = 30 * time.Second
+
+// Operations of a config change
+const (
+	ConfigSet    = "set"
+	ConfigDelete = "delete"
+	ConfigAppend = "append"
+)
+
+var configOps = map[string]kafka.AlterConfigOpType{
+	ConfigSet:    kafka.AlterConfigOpTypeSet,
+	ConfigDelete: kafka.AlterConfigOpTypeDelete,
+	ConfigAppend: kafka.AlterConfigOpTypeAppend,
+}
+
+// TopicSpec describes a topic to create with its config entries.
+type TopicSpec struct {
+	Name              string
+	Partitions        int
+	ReplicationFactor int
+	Config            map[string]string
+	ValidateOnly      bool
+}
+
+// TopicPresets are named sets of topic configs for common setups.
+var TopicPresets = map[string]map[string]string{
+	"compacted":        {"cleanup.policy": "compact"},
+	"compacted-delete": {"cleanup.policy": "compact,delete"},
+	"1-day-retention":  {"retention.ms": "86400000"},
+	"7-day-retention":  {"retention.ms": "604800000"},
+	"30-day-retention": {"retention.ms": "2592000000"},
+}
+
+// TopicPresetNames returns the names of TopicPresets sorted.
+func TopicPresetNames() []string {
+	names := make([]string, 0, len(TopicPresets))
+	for name := range TopicPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TopicConfigFromPresets merges the presets in order and then the explicit
+// configs, so later presets and explicit values win.
+func TopicConfigFromPresets(presets []string, config map[string]string) (map[string]string, error) {
+	merged := make(map[string]string)
+	for _, name := range presets {
+		preset, ok := TopicPresets[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown preset '%s', expected one of %s", name, strings.Join(TopicPresetNames(), ", "))
+		}
+		maps.Copy(merged, preset)
+	}
+	maps.Copy(merged, config)
+	return merged, nil
+}
+
+// ConfigValue is the effective value of one config and where it comes from.
+type ConfigValue struct {
+	Name      string
+	Value     string
+	Source    string
+	Default   bool
+	ReadOnly  bool
+	Sensitive bool
+}
+
+// ConfigChange sets, deletes (back to the default) or appends to a config.
+// Append adds an entry to a list config such as cleanup.policy.
+type ConfigChange struct {
+	Op    string
+	Name  string
+	Value string
+}
+
+func (c ConfigChange) String() string {
+	if c.Op == ConfigDelete {
+		return fmt.Sprintf("%s %s", c.Op, c.Name)
+	}
+	return fmt.Sprintf("%s %s=%s", c.Op, c.Name, c.Value)
+}
+
+// ParseConfigChange parses "name=value" for set and append, or "name" for
+// delete.
+func ParseConfigChange(op, s string) (ConfigChange, error) {
+	if _, ok := configOps[op]; !ok {
+		return ConfigChange{}, fmt.Errorf("unknown config operation '%s'", op)
+	}
+	if op == ConfigDelete {
+		name := strings.TrimSpace(s)
+		if name == "" || strings.Contains(name, "=") {
+			return ConfigChange{}, fmt.Errorf("invalid config '%s', expected a name", s)
+		}
+		return ConfigChange{Op: op, Name: name}, nil
+	}
+	name, value, ok := strings.Cut(s, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return ConfigChange{}, fmt.Errorf("invalid config '%s', expected name=value", s)
+	}
+	return ConfigChange{Op: op, Name: name, Value: value}, nil
+}
+
+// ConfigSourceName names a config source as default, static or dynamic with
+// the level it was set on.
+func ConfigSourceName(source kafka.ConfigSource) string {
+	switch source {
+	case kafka.ConfigSourceDefault:
+		return "default"
+	case kafka.ConfigSourceStaticBroker:
+		return "static broker"
+	case kafka.ConfigSourceDynamicTopic:
+		return "dynamic topic"
+	case kafka.ConfigSourceDynamicBroker:
+		return "dynamic broker"
+	case kafka.ConfigSourceDynamicDefaultBroker:
+		return "dynamic cluster default"
+	}
+	return "unknown"
+}
+
+// DescribeTopicConfig returns every config of the topic sorted by name.
+func (ka *KafAdmin) DescribeTopicConfig(topic string) ([]ConfigValue, error) {
+	return ka.describeConfigs(kafka.ResourceTopic, topic)
+}
+
+// AlterTopicConfig applies the changes to the topic in one request. Configs
+// not named in changes keep their values.
+func (ka *KafAdmin) AlterTopicConfig(topic string, changes []ConfigChange) error {
+	return ka.alterConfigs(kafka.ResourceTopic, topic, changes)
+}
+
+func (ka *KafAdmin) describeConfigs(resType kafka.ResourceType, name string) ([]ConfigValue, error) {
+
+	logger.Info("Describing configs", "type", resType, "name", name)
+
+	ctx, cancel := context.WithTimeout(context.Background(), configRequestTimeout)
+	defer cancel()
+
+	results, err := ka.admin.DescribeConfigs(ctx,
+		[]kafka.ConfigResource{{Type: resType, Name: name}},
+		kafka.SetAdminRequestTimeout(configRequestTimeout))
+	if err != nil {
+		logger.Error("Failed to describe configs", "type", resType, "name", name, "error", err)
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no configs returned for %s '%s'", resType, name)
+	}
+	if results[0].Error.Code() != kafka.ErrNoError {
+		return nil, fmt.Errorf("describing configs of %s '%s': %w", resType, name, results[0].Error)
+	}
+
+	configs := make([]ConfigValue, 0, len(results[0].Config))
+	for _, entry := range results[0].Config {
+		configs = append(configs, ConfigValue{
+			Name:      entry.Name,
+			Value:     entry.Value,
+			Source:    ConfigSourceName(entry.Source),
+			Default:   entry.IsDefault || entry.Source == kafka.ConfigSourceDefault,
+			ReadOnly:  entry.IsReadOnly,
+			Sensitive: entry.IsSensitive,
+		})
+	}
+	sort.Slice(configs, func(i, j int) bool { return configs[i].Name < configs[j].Name })
+	return configs, nil
+}
+
+func (ka *KafAdmin) alterConfigs(resType kafka.ResourceType, name string, changes []ConfigChange) error {
+
+	logger.Info("Altering configs", "type", resType, "name", name, "changes", changes)
+	if len(changes) == 0 {
+		return errors.New("no config changes given")
+	}
+
+	entries := make([]kafka.ConfigEntry, 0, len(changes))
+	for _, c := range changes {
+		op, ok := configOps[c.Op]
+		if !ok {
+			return fmt.Errorf("unknown config operation '%s'", c.Op)
+		}
+		entries = append(entries, kafka.ConfigEntry{Name: c.Name, Value: c.Value, IncrementalOperation: op})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), configRequestTimeout)
+	defer cancel()
+
+	results, err := ka.admin.IncrementalAlterConfigs(ctx,
+		[]kafka.ConfigResource{{Type: resType, Name: name, Config: entries}},
+		kafka.SetAdminRequestTimeout(configRequestTimeout))
+	if err != nil {
+		logger.Error("Failed to alter configs", "type", resType, "name", name, "error", err)
+		return err
+	}
+	for _, r := range results {
+		if r.Error.Code() != kafka.ErrNoError {
+			return fmt.Errorf("altering configs of %s '%s': %w", resType, name, r.Error)
+		}
+	}
+	return nil
+}
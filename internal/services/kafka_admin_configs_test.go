@@ -0,0 +1,118 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_DescribeTopicConfig(t *testing.T) {
+
+	kafAdmin, mockAdmin, resetAdmin, err := setup(t)
+	assert.NoError(t, err)
+	defer resetAdmin()
+
+	results := []kafka.ConfigResourceResult{{
+		Type: kafka.ResourceTopic,
+		Name: "orders",
+		Config: map[string]kafka.ConfigEntryResult{
+			"retention.ms":        {Name: "retention.ms", Value: "86400000", Source: kafka.ConfigSourceDynamicTopic},
+			"cleanup.policy":      {Name: "cleanup.policy", Value: "delete", Source: kafka.ConfigSourceDefault, IsDefault: true},
+			"min.insync.replicas": {Name: "min.insync.replicas", Value: "2", Source: kafka.ConfigSourceStaticBroker},
+		},
+	}}
+	mockAdmin.On("DescribeConfigs", mock.Anything, []kafka.ConfigResource{{Type: kafka.ResourceTopic, Name: "orders"}}, mock.Anything).
+		Return(results, nil)
+
+	configs, err := kafAdmin.DescribeTopicConfig("orders")
+	assert.NoError(t, err)
+	assert.Equal(t, []ConfigValue{
+		{Name: "cleanup.policy", Value: "delete", Source: "default", Default: true},
+		{Name: "min.insync.replicas", Value: "2", Source: "static broker"},
+		{Name: "retention.ms", Value: "86400000", Source: "dynamic topic"},
+	}, configs)
+}
+
+func Test_AlterTopicConfig(t *testing.T) {
+
+	kafAdmin, mockAdmin, resetAdmin, err := setup(t)
+	assert.NoError(t, err)
+	defer resetAdmin()
+
+	expected := []kafka.ConfigResource{{Type: kafka.ResourceTopic, Name: "orders", Config: []kafka.ConfigEntry{
+		{Name: "retention.ms", Value: "3600000", IncrementalOperation: kafka.AlterConfigOpTypeSet},
+		{Name: "min.insync.replicas", IncrementalOperation: kafka.AlterConfigOpTypeDelete},
+		{Name: "cleanup.policy", Value: "compact", IncrementalOperation: kafka.AlterConfigOpTypeAppend},
+	}}}
+	mockAdmin.On("IncrementalAlterConfigs", mock.Anything, expected, mock.Anything).
+		Return([]kafka.ConfigResourceResult{{Type: kafka.ResourceTopic, Name: "orders"}}, nil).Once()
+
+	err = kafAdmin.AlterTopicConfig("orders", []ConfigChange{
+		{Op: ConfigSet, Name: "retention.ms", Value: "3600000"},
+		{Op: ConfigDelete, Name: "min.insync.replicas"},
+		{Op: ConfigAppend, Name: "cleanup.policy", Value: "compact"},
+	})
+	assert.NoError(t, err)
+
+	mockAdmin.On("IncrementalAlterConfigs", mock.Anything, mock.Anything, mock.Anything).
+		Return([]kafka.ConfigResourceResult{{Name: "orders", Error: kafka.NewError(kafka.ErrInvalidConfig, "invalid retention.ms", false)}}, nil)
+	err = kafAdmin.AlterTopicConfig("orders", []ConfigChange{{Op: ConfigSet, Name: "retention.ms", Value: "soon"}})
+	assert.ErrorContains(t, err, "invalid retention.ms")
+}
+
+func Test_ParseConfigChange(t *testing.T) {
+
+	change, err := ParseConfigChange(ConfigSet, "retention.ms=604800000")
+	assert.NoError(t, err)
+	assert.Equal(t, ConfigChange{Op: ConfigSet, Name: "retention.ms", Value: "604800000"}, change)
+
+	change, err = ParseConfigChange(ConfigAppend, "cleanup.policy=compact")
+	assert.NoError(t, err)
+	assert.Equal(t, "compact", change.Value)
+
+	change, err = ParseConfigChange(ConfigDelete, "retention.ms")
+	assert.NoError(t, err)
+	assert.Equal(t, ConfigChange{Op: ConfigDelete, Name: "retention.ms"}, change)
+
+	for _, tc := range []struct{ op, s string }{
+		{ConfigSet, "retention.ms"},
+		{ConfigSet, "=1"},
+		{ConfigDelete, "retention.ms=1"},
+		{"subtract", "cleanup.policy=compact"},
+	} {
+		_, err := ParseConfigChange(tc.op, tc.s)
+		assert.Error(t, err, "%s %s", tc.op, tc.s)
+	}
+}
+
+func Test_CreateTopicWithConfig(t *testing.T) {
+
+	kafAdmin, mockAdmin, resetAdmin, err := setup(t)
+	assert.NoError(t, err)
+	defer resetAdmin()
+
+	config := map[string]string{"cleanup.policy": "compact", "min.insync.replicas": "2"}
+	expected := []kafka.TopicSpecification{{Topic: "customers", NumPartitions: 6, ReplicationFactor: 3, Config: config}}
+	mockAdmin.On("CreateTopics", mock.Anything, expected, mock.Anything).
+		Return([]kafka.TopicResult{{Topic: "customers"}}, nil).Once()
+
+	err = kafAdmin.CreateTopicWithConfig(TopicSpec{Name: "customers", Partitions: 6, ReplicationFactor: 3, Config: config, ValidateOnly: true})
+	assert.NoError(t, err)
+
+	mockAdmin.On("CreateTopics", mock.Anything, mock.Anything, mock.Anything).
+		Return([]kafka.TopicResult{{Topic: "customers", Error: kafka.NewError(kafka.ErrInvalidConfig, "Invalid value x for retention.ms", false)}}, nil)
+	err = kafAdmin.CreateTopicWithConfig(TopicSpec{Name: "customers", Partitions: 1, ReplicationFactor: 1, ValidateOnly: true})
+	assert.ErrorContains(t, err, "retention.ms")
+}
+
+func Test_TopicConfigFromPresets(t *testing.T) {
+
+	config, err := TopicConfigFromPresets([]string{"compacted", "7-day-retention"}, map[string]string{"retention.ms": "1000"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"cleanup.policy": "compact", "retention.ms": "1000"}, config)
+
+	_, err = TopicConfigFromPresets([]string{"forever"}, nil)
+	assert.ErrorContains(t, err, "unknown preset 'forever'")
+}
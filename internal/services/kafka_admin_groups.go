@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kafctl/internal/logger"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// Timeout for consumer group admin requests
+const groupRequestTimeout = 30 * time.Second
+
+// ListConsumerGroups returns all consumer groups known to the cluster sorted
+// by group id. Errors from single brokers are logged and only returned when
+// no broker answered.
+func (ka *KafAdmin) ListConsumerGroups() ([]kafka.ConsumerGroupListing, error) {
+
+	logger.Info("Listing consumer groups")
+
+	ctx, cancel := context.WithTimeout(context.Background(), groupRequestTimeout)
+	defer cancel()
+
+	result, err := ka.admin.ListConsumerGroups(ctx, kafka.SetAdminRequestTimeout(groupRequestTimeout))
+	if err != nil {
+		logger.Error("Failed to list consumer groups", "error", err)
+		return nil, err
+	}
+	for _, e := range result.Errors {
+		logger.Warn("Error listing consumer groups", "error", e)
+	}
+	if len(result.Valid) == 0 && len(result.Errors) > 0 {
+		return nil, fmt.Errorf("failed to list consumer groups: %w", errors.Join(result.Errors...))
+	}
+
+	groups := result.Valid
+	sort.Slice(groups, func(i, j int) bool { return groups[i].GroupID < groups[j].GroupID })
+	logger.Info("Total number of consumer groups", "total", len(groups))
+	return groups, nil
+}
+
+// DescribeConsumerGroups returns state, coordinator and members with their
+// assigned partitions of the groups. A group that could not be described
+// carries its error in the description.
+func (ka *KafAdmin) DescribeConsumerGroups(groups []string) ([]kafka.ConsumerGroupDescription, error) {
+
+	logger.Info("Describing consumer groups", "groups", groups)
+	if len(groups) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), groupRequestTimeout)
+	defer cancel()
+
+	result, err := ka.admin.DescribeConsumerGroups(ctx, groups, kafka.SetAdminRequestTimeout(groupRequestTimeout))
+	if err != nil {
+		logger.Error("Failed to describe consumer groups", "error", err)
+		return nil, err
+	}
+
+	descriptions := result.ConsumerGroupDescriptions
+	for _, d := range descriptions {
+		if d.Error.Code() != kafka.ErrNoError {
+			logger.Warn("Consumer group has error", "group", d.GroupID, "error", d.Error)
+			continue
+		}
+		logger.Debug("Described consumer group", "group", d.GroupID, "state", d.State, "members", len(d.Members))
+	}
+	sort.Slice(descriptions, func(i, j int) bool { return descriptions[i].GroupID < descriptions[j].GroupID })
+	return descriptions, nil
+}
+
+// ListConsumerGroupOffsets returns the committed offsets of all partitions the
+// group has committed to, sorted by topic and partition.
+func (ka *KafAdmin) ListConsumerGroupOffsets(group string) ([]kafka.TopicPartition, error) {
+
+	logger.Info("Listing consumer group offsets", "group", group)
+
+	ctx, cancel := context.WithTimeout(context.Background(), groupRequestTimeout)
+	defer cancel()
+
+	result, err := ka.admin.ListConsumerGroupOffsets(ctx,
+		[]kafka.ConsumerGroupTopicPartitions{{Group: group}},
+		kafka.SetAdminRequireStableOffsets(false))
+	if err != nil {
+		logger.Error("Failed to list consumer group offsets", "group", group, "error", err)
+		return nil, err
+	}
+	if len(result.ConsumerGroupsTopicPartitions) == 0 {
+		return nil, fmt.Errorf("no offsets returned for consumer group '%s'", group)
+	}
+
+	var offsets []kafka.TopicPartition
+	for _, tp := range result.ConsumerGroupsTopicPartitions[0].Partitions {
+		if tp.Error != nil {
+			logger.Warn("Error in committed offset", "group", group, "topic", *tp.Topic, "partition", tp.Partition, "error", tp.Error)
+		}
+		offsets = append(offsets, tp)
+	}
+	sortTopicPartitions(offsets)
+	return offsets, nil
+}
+
+// GroupSelector picks consumer groups for bulk operations. Pattern matches
+// the group id. EmptyFor keeps only groups without members that have been
+// idle for that time. Kafka records neither when a group became empty nor
+// when it last committed, so idleness is judged from message timestamps, see
+// idleSince; groups that cannot be judged are not selected.
+type GroupSelector struct {
+	Pattern  *regexp.Regexp
+	EmptyFor time.Duration
+}
+
+// SelectConsumerGroups returns the ids of the groups matching the selector,
+// sorted by group id.
+func (ka *KafAdmin) SelectConsumerGroups(selector GroupSelector) ([]string, error) {
+
+	listings, err := ka.ListConsumerGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	since := time.Now().Add(-selector.EmptyFor)
+	var selected []string
+	for _, g := range listings {
+		if selector.Pattern != nil && !selector.Pattern.MatchString(g.GroupID) {
+			continue
+		}
+		if selector.EmptyFor > 0 {
+			if g.State != kafka.ConsumerGroupStateEmpty {
+				continue
+			}
+			idle, err := ka.idleSince(g.GroupID, since)
+			if err != nil {
+				return nil, err
+			}
+			if !idle {
+				continue
+			}
+		}
+		selected = append(selected, g.GroupID)
+	}
+	logger.Info("Selected consumer groups", "pattern", selector.Pattern, "emptyFor", selector.EmptyFor, "total", len(selected))
+	return selected, nil
+}
+
+// idleSince reports whether the committed offsets of the group show that it
+// has not consumed since that time. The group is active when it consumed a
+// message produced at or after since on any partition. It is idle only when
+// on some partition the next message it would read was produced before since,
+// so a running consumer would have read it long ago. A group that is caught up
+// on every partition, e.g. on a quiet topic, or has no valid committed
+// offsets cannot be judged and is reported as not idle.
+func (ka *KafAdmin) idleSince(group string, since time.Time) (bool, error) {
+	committed, err := ka.ListConsumerGroupOffsets(group)
+	if err != nil {
+		return false, err
+	}
+	var partitions []kafka.TopicPartition
+	for _, tp := range committed {
+		if tp.Error == nil && tp.Offset >= 0 {
+			partitions = append(partitions, tp)
+		}
+	}
+	if len(partitions) == 0 {
+		logger.Info("Cannot judge idleness without committed offsets", "group", group)
+		return false, nil
+	}
+
+	earliest, err := ka.offsetsBySpec(partitions, kafka.EarliestOffsetSpec)
+	if err != nil {
+		return false, err
+	}
+	latest, err := ka.offsetsBySpec(partitions, kafka.LatestOffsetSpec)
+	if err != nil {
+		return false, err
+	}
+	// first offset at or after since, -1 when every message is older
+	first, err := ka.offsetsBySpec(partitions, kafka.NewOffsetSpecForTimestamp(since.UnixMilli()))
+	if err != nil {
+		return false, err
+	}
+
+	idle := false
+	for _, tp := range partitions {
+		topic, committed := *tp.Topic, int64(tp.Offset)
+		firstSince, ok := first[topic][tp.Partition]
+		if ok && firstSince >= 0 && committed > firstSince {
+			return false, nil
+		}
+		// the next message to read, records below the earliest offset are gone
+		next := max(committed, earliest[topic][tp.Partition])
+		if ok && next < latest[topic][tp.Partition] && (firstSince < 0 || next < firstSince) {
+			idle = true
+		}
+	}
+	if !idle {
+		logger.Info("Cannot judge idleness of a caught up group", "group", group)
+	}
+	return idle, nil
+}
+
+// DeleteConsumerGroups deletes the groups. Groups that still have members are
+// refused with ErrNonEmptyGroup without being sent to the cluster. The results
+// are sorted by group id.
+func (ka *KafAdmin) DeleteConsumerGroups(groups []string) ([]kafka.ConsumerGroupResult, error) {
+
+	logger.Info("Deleting consumer groups", "groups", groups)
+	if len(groups) == 0 {
+		return nil, nil
+	}
+
+	descriptions, err := ka.DescribeConsumerGroups(groups)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []kafka.ConsumerGroupResult
+	var deletable []string
+	for _, d := range descriptions {
+		switch {
+		case d.Error.Code() != kafka.ErrNoError:
+			results = append(results, kafka.ConsumerGroupResult{Group: d.GroupID, Error: d.Error})
+		case len(d.Members) > 0 || (d.State != kafka.ConsumerGroupStateEmpty && d.State != kafka.ConsumerGroupStateDead):
+			msg := fmt.Sprintf("group is %s with %d members", d.State, len(d.Members))
+			results = append(results, kafka.ConsumerGroupResult{Group: d.GroupID, Error: kafka.NewError(kafka.ErrNonEmptyGroup, msg, false)})
+		default:
+			deletable = append(deletable, d.GroupID)
+		}
+	}
+
+	if len(deletable) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), groupRequestTimeout)
+		defer cancel()
+
+		result, err := ka.admin.DeleteConsumerGroups(ctx, deletable, kafka.SetAdminRequestTimeout(groupRequestTimeout))
+		if err != nil {
+			logger.Error("Failed to delete consumer groups", "error", err)
+			return nil, err
+		}
+		results = append(results, result.ConsumerGroupResults...)
+	}
+
+	for _, r := range results {
+		if r.Error.Code() != kafka.ErrNoError {
+			logger.Warn("Consumer group not deleted", "group", r.Group, "error", r.Error)
+		} else {
+			logger.Info("Deleted consumer group", "group", r.Group)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Group < results[j].Group })
+	return results, nil
+}
+
+func sortTopicPartitions(tps []kafka.TopicPartition) {
+	sort.Slice(tps, func(i, j int) bool {
+		if *tps[i].Topic != *tps[j].Topic {
+			return *tps[i].Topic < *tps[j].Topic
+		}
+		return tps[i].Partition < tps[j].Partition
+	})
+}
+
+// FormatAssignment renders partitions as topic[0,1,2], one entry per topic in
+// order of first appearance.
+func FormatAssignment(tps []kafka.TopicPartition) string {
+	var topics []string
+	partitions := make(map[string][]string)
+	for _, tp := range tps {
+		if _, ok := partitions[*tp.Topic]; !ok {
+			topics = append(topics, *tp.Topic)
+		}
+		partitions[*tp.Topic] = append(partitions[*tp.Topic], strconv.Itoa(int(tp.Partition)))
+	}
+	parts := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		parts = append(parts, fmt.Sprintf("%s[%s]", topic, strings.Join(partitions[topic], ",")))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_ListConsumerGroups(t *testing.T) {
+
+	kafAdmin, mockAdmin, resetAdmin, err := setup(t)
+	assert.NoError(t, err)
+	defer resetAdmin()
+
+	listRes := kafka.ListConsumerGroupsResult{
+		Valid: []kafka.ConsumerGroupListing{
+			{GroupID: "orders-service", State: kafka.ConsumerGroupStateStable},
+			{GroupID: "billing", State: kafka.ConsumerGroupStateEmpty},
+		},
+		Errors: []error{errors.New("broker 2 unavailable")},
+	}
+	mockAdmin.On("ListConsumerGroups", mock.Anything, mock.Anything).Return(listRes, nil)
+
+	groups, err := kafAdmin.ListConsumerGroups()
+	assert.NoError(t, err)
+	assert.Len(t, groups, 2)
+	assert.Equal(t, "billing", groups[0].GroupID)
+	assert.Equal(t, "orders-service", groups[1].GroupID)
+}
+
+func Test_ListConsumerGroups_AllFailed(t *testing.T) {
+
+	kafAdmin, mockAdmin, resetAdmin, err := setup(t)
+	assert.NoError(t, err)
+	defer resetAdmin()
+
+	listRes := kafka.ListConsumerGroupsResult{Errors: []error{errors.New("broker 1 unavailable")}}
+	mockAdmin.On("ListConsumerGroups", mock.Anything, mock.Anything).Return(listRes, nil)
+
+	_, err = kafAdmin.ListConsumerGroups()
+	assert.ErrorContains(t, err, "broker 1 unavailable")
+}
+
+func Test_DescribeConsumerGroups(t *testing.T) {
+
+	kafAdmin, mockAdmin, resetAdmin, err := setup(t)
+	assert.NoError(t, err)
+	defer resetAdmin()
+
+	topic := "Aatest-orders"
+	descRes := kafka.DescribeConsumerGroupsResult{
+		ConsumerGroupDescriptions: []kafka.ConsumerGroupDescription{{
+			GroupID:     "orders-service",
+			State:       kafka.ConsumerGroupStateStable,
+			Coordinator: kafka.Node{ID: 1, Host: "localhost", Port: 9092},
+			Members: []kafka.MemberDescription{{
+				ConsumerID: "consumer-1",
+				Assignment: kafka.MemberAssignment{TopicPartitions: []kafka.TopicPartition{{Topic: &topic, Partition: 0}}},
+			}},
+		}},
+	}
+	mockAdmin.On("DescribeConsumerGroups", mock.Anything, []string{"orders-service"}, mock.Anything).Return(descRes, nil)
+
+	groups, err := kafAdmin.DescribeConsumerGroups([]string{"orders-service"})
+	assert.NoError(t, err)
+	assert.Len(t, groups, 1)
+	assert.Equal(t, 1, groups[0].Coordinator.ID)
+	assert.Len(t, groups[0].Members[0].Assignment.TopicPartitions, 1)
+
+	groups, err = kafAdmin.DescribeConsumerGroups(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, groups)
+	mockAdmin.AssertNumberOfCalls(t, "DescribeConsumerGroups", 1)
+}
+
+func Test_ListConsumerGroupOffsets(t *testing.T) {
+
+	kafAdmin, mockAdmin, resetAdmin, err := setup(t)
+	assert.NoError(t, err)
+	defer resetAdmin()
+
+	orders, billing := "Aatest-orders", "Aatest-billing"
+	offsetsRes := kafka.ListConsumerGroupOffsetsResult{
+		ConsumerGroupsTopicPartitions: []kafka.ConsumerGroupTopicPartitions{{
+			Group: "orders-service",
+			Partitions: []kafka.TopicPartition{
+				{Topic: &orders, Partition: 1, Offset: 20},
+				{Topic: &billing, Partition: 0, Offset: 5},
+				{Topic: &orders, Partition: 0, Offset: 10},
+			},
+		}},
+	}
+	expectedReq := []kafka.ConsumerGroupTopicPartitions{{Group: "orders-service"}}
+	mockAdmin.On("ListConsumerGroupOffsets", mock.Anything, expectedReq, mock.Anything).Return(offsetsRes, nil)
+
+	offsets, err := kafAdmin.ListConsumerGroupOffsets("orders-service")
+	assert.NoError(t, err)
+	assert.Len(t, offsets, 3)
+	assert.Equal(t, billing, *offsets[0].Topic)
+	assert.Equal(t, int32(0), offsets[1].Partition)
+	assert.Equal(t, kafka.Offset(20), offsets[2].Offset)
+}
+
+func Test_DeleteConsumerGroups(t *testing.T) {
+
+	kafAdmin, mockAdmin, resetAdmin, err := setup(t)
+	assert.NoError(t, err)
+	defer resetAdmin()
+
+	descRes := kafka.DescribeConsumerGroupsResult{
+		ConsumerGroupDescriptions: []kafka.ConsumerGroupDescription{
+			{GroupID: "preview-42", State: kafka.ConsumerGroupStateEmpty},
+			{GroupID: "orders-service", State: kafka.ConsumerGroupStateStable,
+				Members: []kafka.MemberDescription{{ConsumerID: "consumer-1"}}},
+		},
+	}
+	mockAdmin.On("DescribeConsumerGroups", mock.Anything, mock.Anything, mock.Anything).Return(descRes, nil)
+	mockAdmin.On("DeleteConsumerGroups", mock.Anything, []string{"preview-42"}, mock.Anything).Return(kafka.DeleteConsumerGroupsResult{
+		ConsumerGroupResults: []kafka.ConsumerGroupResult{{Group: "preview-42"}},
+	}, nil)
+
+	results, err := kafAdmin.DeleteConsumerGroups([]string{"preview-42", "orders-service"})
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, "orders-service", results[0].Group)
+	assert.Equal(t, kafka.ErrNonEmptyGroup, results[0].Error.Code())
+	assert.Equal(t, "preview-42", results[1].Group)
+	assert.Equal(t, kafka.ErrNoError, results[1].Error.Code())
+	mockAdmin.AssertExpectations(t)
+}
+
+func Test_SelectConsumerGroups(t *testing.T) {
+
+	kafAdmin, mockAdmin, resetAdmin, err := setup(t)
+	assert.NoError(t, err)
+	defer resetAdmin()
+
+	orders, audit := "orders", "audit"
+	mockAdmin.On("ListConsumerGroups", mock.Anything, mock.Anything).Return(kafka.ListConsumerGroupsResult{
+		Valid: []kafka.ConsumerGroupListing{
+			{GroupID: "preview-1", State: kafka.ConsumerGroupStateEmpty},
+			{GroupID: "preview-2", State: kafka.ConsumerGroupStateEmpty},
+			{GroupID: "preview-3", State: kafka.ConsumerGroupStateStable},
+			{GroupID: "preview-4", State: kafka.ConsumerGroupStateEmpty},
+			{GroupID: "preview-5", State: kafka.ConsumerGroupStateEmpty},
+			{GroupID: "preview-6", State: kafka.ConsumerGroupStateEmpty},
+			{GroupID: "billing", State: kafka.ConsumerGroupStateEmpty},
+		},
+	}, nil)
+	committed := func(group string, topic *string, offset kafka.Offset) {
+		mockAdmin.On("ListConsumerGroupOffsets", mock.Anything, []kafka.ConsumerGroupTopicPartitions{{Group: group}}, mock.Anything).
+			Return(kafka.ListConsumerGroupOffsetsResult{ConsumerGroupsTopicPartitions: []kafka.ConsumerGroupTopicPartitions{{
+				Group: group, Partitions: []kafka.TopicPartition{{Topic: topic, Partition: 0, Offset: offset}},
+			}}}, nil)
+	}
+	// orders holds offsets 0 to 149 and messages produced within the window
+	// start at offset 100, the quiet audit topic holds 0 to 39, all older
+	offsets := func(orderOffset, auditOffset kafka.Offset) kafka.ListOffsetsResult {
+		res := listOffsetsResult(orders, orderOffset)
+		res.ResultInfos[kafka.TopicPartition{Topic: &audit, Partition: 0}] = kafka.ListOffsetsResultInfo{Offset: auditOffset}
+		return res
+	}
+	mockAdmin.On("ListOffsets", mock.Anything, offsetsFor(kafka.EarliestOffsetSpec), mock.Anything).Return(offsets(0, 0), nil)
+	mockAdmin.On("ListOffsets", mock.Anything, offsetsFor(kafka.LatestOffsetSpec), mock.Anything).Return(offsets(150, 40), nil)
+	mockAdmin.On("ListOffsets", mock.Anything, mock.Anything, mock.Anything).Return(offsets(100, -1), nil)
+
+	committed("preview-1", &orders, 80)  // left older messages unread
+	committed("preview-2", &orders, 120) // read messages of the window
+	committed("preview-4", &audit, 40)   // caught up on a quiet topic
+	committed("preview-5", &audit, 30)   // left older messages unread
+	committed("preview-6", &orders, kafka.OffsetInvalid)
+
+	selected, err := kafAdmin.SelectConsumerGroups(GroupSelector{Pattern: regexp.MustCompile("^preview-")})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"preview-1", "preview-2", "preview-3", "preview-4", "preview-5", "preview-6"}, selected)
+
+	selected, err = kafAdmin.SelectConsumerGroups(GroupSelector{Pattern: regexp.MustCompile("^preview-"), EmptyFor: 7 * 24 * time.Hour})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"preview-1", "preview-5"}, selected)
+}
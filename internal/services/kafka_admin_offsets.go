@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kafctl/internal/logger"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// Modes of an offset reset
+const (
+	ResetToEarliest = "to-earliest"
+	ResetToLatest   = "to-latest"
+	ResetToDatetime = "to-datetime"
+	ResetToOffset   = "to-offset"
+	ResetShiftBy    = "shift-by"
+)
+
+// OffsetResetSpec describes where the offsets of a group are moved to. Offset
+// is used by ResetToOffset, Shift by ResetShiftBy and Time by ResetToDatetime.
+type OffsetResetSpec struct {
+	Mode   string
+	Offset int64
+	Shift  int64
+	Time   time.Time
+}
+
+func (s OffsetResetSpec) String() string {
+	switch s.Mode {
+	case ResetToDatetime:
+		return fmt.Sprintf("%s %s", s.Mode, s.Time.UTC().Format(time.RFC3339))
+	case ResetToOffset:
+		return fmt.Sprintf("%s %d", s.Mode, s.Offset)
+	case ResetShiftBy:
+		return fmt.Sprintf("%s %d", s.Mode, s.Shift)
+	}
+	return s.Mode
+}
+
+// OffsetReset is the planned move of one partition. Current is -1 when the
+// group has not committed an offset for the partition.
+type OffsetReset struct {
+	Topic     string
+	Partition int32
+	Current   int64
+	Target    int64
+}
+
+// ListOffsets returns the offsets matching spec for the partitions, sorted by
+// topic and partition. Offsets are those visible to read committed consumers.
+func (ka *KafAdmin) ListOffsets(partitions []kafka.TopicPartition, spec kafka.OffsetSpec) ([]kafka.TopicPartition, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), groupRequestTimeout)
+	defer cancel()
+
+	request := make(map[kafka.TopicPartition]kafka.OffsetSpec, len(partitions))
+	for _, tp := range partitions {
+		request[kafka.TopicPartition{Topic: tp.Topic, Partition: tp.Partition}] = spec
+	}
+
+	result, err := ka.admin.ListOffsets(ctx, request, kafka.SetAdminIsolationLevel(kafka.IsolationLevelReadCommitted))
+	if err != nil {
+		logger.Error("Failed to list offsets", "error", err)
+		return nil, err
+	}
+
+	var errs []error
+	offsets := make([]kafka.TopicPartition, 0, len(result.ResultInfos))
+	for tp, info := range result.ResultInfos {
+		if info.Error.Code() != kafka.ErrNoError {
+			errs = append(errs, fmt.Errorf("%s[%d]: %w", *tp.Topic, tp.Partition, info.Error))
+			continue
+		}
+		offsets = append(offsets, kafka.TopicPartition{Topic: tp.Topic, Partition: tp.Partition, Offset: info.Offset})
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("failed to list offsets: %w", errors.Join(errs...))
+	}
+	sortTopicPartitions(offsets)
+	return offsets, nil
+}
+
+// AlterConsumerGroupOffsets commits the offsets for the group. The returned
+// partitions carry the error of every partition that could not be altered.
+func (ka *KafAdmin) AlterConsumerGroupOffsets(group string, offsets []kafka.TopicPartition) ([]kafka.TopicPartition, error) {
+
+	logger.Info("Altering consumer group offsets", "group", group, "partitions", len(offsets))
+
+	ctx, cancel := context.WithTimeout(context.Background(), groupRequestTimeout)
+	defer cancel()
+
+	result, err := ka.admin.AlterConsumerGroupOffsets(ctx,
+		[]kafka.ConsumerGroupTopicPartitions{{Group: group, Partitions: offsets}},
+		kafka.SetAdminRequestTimeout(groupRequestTimeout))
+	if err != nil {
+		logger.Error("Failed to alter consumer group offsets", "group", group, "error", err)
+		return nil, err
+	}
+	if len(result.ConsumerGroupsTopicPartitions) == 0 {
+		return nil, fmt.Errorf("no result returned for consumer group '%s'", group)
+	}
+
+	altered := result.ConsumerGroupsTopicPartitions[0].Partitions
+	sortTopicPartitions(altered)
+	return altered, nil
+}
+
+// PlanOffsetReset computes current and target offset of every partition of
+// topic, or of every partition the group committed to when topic is empty.
+// Targets are kept between the earliest and latest offset of the partition.
+func (ka *KafAdmin) PlanOffsetReset(group, topic string, spec OffsetResetSpec) ([]OffsetReset, error) {
+
+	logger.Info("Planning offset reset", "group", group, "topic", topic, "spec", spec.String())
+
+	committed, err := ka.ListConsumerGroupOffsets(group)
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[string]map[int32]int64)
+	var partitions []kafka.TopicPartition
+	for _, tp := range committed {
+		if topic != "" && *tp.Topic != topic {
+			continue
+		}
+		if current[*tp.Topic] == nil {
+			current[*tp.Topic] = make(map[int32]int64)
+		}
+		current[*tp.Topic][tp.Partition] = int64(tp.Offset)
+		if topic == "" {
+			partitions = append(partitions, kafka.TopicPartition{Topic: tp.Topic, Partition: tp.Partition})
+		}
+	}
+
+	if topic != "" {
+		partitions, err = ka.topicPartitions(topic)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(partitions) == 0 {
+		return nil, fmt.Errorf("group '%s' has no committed offsets, a topic is required", group)
+	}
+
+	earliest, err := ka.offsetsBySpec(partitions, kafka.EarliestOffsetSpec)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := ka.offsetsBySpec(partitions, kafka.LatestOffsetSpec)
+	if err != nil {
+		return nil, err
+	}
+	var byTime map[string]map[int32]int64
+	if spec.Mode == ResetToDatetime {
+		byTime, err = ka.offsetsBySpec(partitions, kafka.NewOffsetSpecForTimestamp(spec.Time.UnixMilli()))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	plan := make([]OffsetReset, 0, len(partitions))
+	for _, tp := range partitions {
+		t, p := *tp.Topic, tp.Partition
+		cur, ok := current[t][p]
+		if !ok || cur < 0 {
+			cur = -1
+		}
+		low, high := earliest[t][p], latest[t][p]
+
+		var target int64
+		switch spec.Mode {
+		case ResetToEarliest:
+			target = low
+		case ResetToLatest:
+			target = high
+		case ResetToDatetime:
+			// no message at or after the time
+			target = byTime[t][p]
+			if target < 0 {
+				target = high
+			}
+		case ResetToOffset:
+			target = spec.Offset
+		case ResetShiftBy:
+			if cur < 0 {
+				logger.Warn("Skipping partition without committed offset", "group", group, "topic", t, "partition", p)
+				continue
+			}
+			target = cur + spec.Shift
+		default:
+			return nil, fmt.Errorf("unknown reset mode '%s'", spec.Mode)
+		}
+		plan = append(plan, OffsetReset{Topic: t, Partition: p, Current: cur, Target: min(max(target, low), high)})
+	}
+	return plan, nil
+}
+
+// ResetConsumerGroupOffsets commits the targets of the plan. Kafka only
+// accepts new offsets for groups without active members, so the group is
+// checked first to fail with a clear message.
+func (ka *KafAdmin) ResetConsumerGroupOffsets(group string, plan []OffsetReset) error {
+
+	if len(plan) == 0 {
+		return fmt.Errorf("no partitions to reset for group '%s'", group)
+	}
+
+	groups, err := ka.DescribeConsumerGroups([]string{group})
+	if err != nil {
+		return err
+	}
+	if len(groups) > 0 && len(groups[0].Members) > 0 {
+		return fmt.Errorf("group '%s' has %d active members, stop its consumers before resetting offsets",
+			group, len(groups[0].Members))
+	}
+
+	offsets := make([]kafka.TopicPartition, 0, len(plan))
+	for _, r := range plan {
+		topic := r.Topic
+		offsets = append(offsets, kafka.TopicPartition{Topic: &topic, Partition: r.Partition, Offset: kafka.Offset(r.Target)})
+	}
+
+	altered, err := ka.AlterConsumerGroupOffsets(group, offsets)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, tp := range altered {
+		if tp.Error != nil {
+			errs = append(errs, fmt.Errorf("%s[%d]: %w", *tp.Topic, tp.Partition, tp.Error))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to reset offsets of group '%s': %w", group, errors.Join(errs...))
+	}
+	logger.Info("Reset consumer group offsets", "group", group, "partitions", len(altered))
+	return nil
+}
+
+func (ka *KafAdmin) topicPartitions(topic string) ([]kafka.TopicPartition, error) {
+	desc, err := ka.DescribeTopic(topic)
+	if err != nil {
+		return nil, err
+	}
+	if len(desc.TopicDescriptions) == 0 {
+		return nil, fmt.Errorf("topic '%s' not found", topic)
+	}
+	td := desc.TopicDescriptions[0]
+	if td.Error.Code() != kafka.ErrNoError {
+		return nil, fmt.Errorf("describing topic '%s': %w", topic, td.Error)
+	}
+	partitions := make([]kafka.TopicPartition, 0, len(td.Partitions))
+	for _, p := range td.Partitions {
+		partitions = append(partitions, kafka.TopicPartition{Topic: &topic, Partition: int32(p.Partition)})
+	}
+	return partitions, nil
+}
+
+func (ka *KafAdmin) offsetsBySpec(partitions []kafka.TopicPartition, spec kafka.OffsetSpec) (map[string]map[int32]int64, error) {
+	offsets, err := ka.ListOffsets(partitions, spec)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]map[int32]int64)
+	for _, tp := range offsets {
+		if res[*tp.Topic] == nil {
+			res[*tp.Topic] = make(map[int32]int64)
+		}
+		res[*tp.Topic][tp.Partition] = int64(tp.Offset)
+	}
+	return res, nil
+}
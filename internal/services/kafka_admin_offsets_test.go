@@ -0,0 +1,112 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// offsetsFor matches a ListOffsets request for spec
+func offsetsFor(spec kafka.OffsetSpec) any {
+	return mock.MatchedBy(func(req map[kafka.TopicPartition]kafka.OffsetSpec) bool {
+		for _, s := range req {
+			return s == spec
+		}
+		return false
+	})
+}
+
+func listOffsetsResult(topic string, offsets ...kafka.Offset) kafka.ListOffsetsResult {
+	res := kafka.ListOffsetsResult{ResultInfos: map[kafka.TopicPartition]kafka.ListOffsetsResultInfo{}}
+	for p, o := range offsets {
+		res.ResultInfos[kafka.TopicPartition{Topic: &topic, Partition: int32(p)}] = kafka.ListOffsetsResultInfo{Offset: o}
+	}
+	return res
+}
+
+func Test_PlanOffsetReset(t *testing.T) {
+
+	topic := "orders"
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		spec OffsetResetSpec
+		want []int64
+	}{
+		{"earliest", OffsetResetSpec{Mode: ResetToEarliest}, []int64{10, 0}},
+		{"latest", OffsetResetSpec{Mode: ResetToLatest}, []int64{100, 50}},
+		{"datetime", OffsetResetSpec{Mode: ResetToDatetime, Time: at}, []int64{60, 50}},
+		{"offset clamped", OffsetResetSpec{Mode: ResetToOffset, Offset: 70}, []int64{70, 50}},
+		{"shift clamped", OffsetResetSpec{Mode: ResetShiftBy, Shift: -30}, []int64{10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kafAdmin, mockAdmin, resetAdmin, err := setup(t)
+			assert.NoError(t, err)
+			defer resetAdmin()
+
+			mockAdmin.On("ListConsumerGroupOffsets", mock.Anything, mock.Anything, mock.Anything).Return(kafka.ListConsumerGroupOffsetsResult{
+				ConsumerGroupsTopicPartitions: []kafka.ConsumerGroupTopicPartitions{{Group: "billing", Partitions: []kafka.TopicPartition{
+					{Topic: &topic, Partition: 0, Offset: 20},
+					{Topic: &topic, Partition: 1, Offset: kafka.OffsetInvalid},
+				}}},
+			}, nil)
+			mockAdmin.On("ListOffsets", mock.Anything, offsetsFor(kafka.EarliestOffsetSpec), mock.Anything).Return(listOffsetsResult(topic, 10, 0), nil)
+			mockAdmin.On("ListOffsets", mock.Anything, offsetsFor(kafka.LatestOffsetSpec), mock.Anything).Return(listOffsetsResult(topic, 100, 50), nil)
+			mockAdmin.On("ListOffsets", mock.Anything, offsetsFor(kafka.NewOffsetSpecForTimestamp(at.UnixMilli())), mock.Anything).
+				Return(listOffsetsResult(topic, 60, -1), nil)
+
+			plan, err := kafAdmin.PlanOffsetReset("billing", "", tt.spec)
+			assert.NoError(t, err)
+			var targets []int64
+			for _, r := range plan {
+				targets = append(targets, r.Target)
+			}
+			assert.Equal(t, tt.want, targets)
+			assert.Equal(t, int64(20), plan[0].Current)
+		})
+	}
+}
+
+func Test_ResetConsumerGroupOffsets(t *testing.T) {
+
+	kafAdmin, mockAdmin, resetAdmin, err := setup(t)
+	assert.NoError(t, err)
+	defer resetAdmin()
+
+	topic := "orders"
+	mockAdmin.On("DescribeConsumerGroups", mock.Anything, []string{"billing"}, mock.Anything).Return(kafka.DescribeConsumerGroupsResult{
+		ConsumerGroupDescriptions: []kafka.ConsumerGroupDescription{{GroupID: "billing", State: kafka.ConsumerGroupStateEmpty}},
+	}, nil)
+	expected := []kafka.ConsumerGroupTopicPartitions{{Group: "billing", Partitions: []kafka.TopicPartition{{Topic: &topic, Partition: 0, Offset: 10}}}}
+	mockAdmin.On("AlterConsumerGroupOffsets", mock.Anything, expected, mock.Anything).
+		Return(kafka.AlterConsumerGroupOffsetsResult{ConsumerGroupsTopicPartitions: expected}, nil)
+
+	err = kafAdmin.ResetConsumerGroupOffsets("billing", []OffsetReset{{Topic: topic, Partition: 0, Current: 20, Target: 10}})
+	assert.NoError(t, err)
+	mockAdmin.AssertExpectations(t)
+}
+
+func Test_ResetConsumerGroupOffsets_ActiveGroup(t *testing.T) {
+
+	kafAdmin, mockAdmin, resetAdmin, err := setup(t)
+	assert.NoError(t, err)
+	defer resetAdmin()
+
+	mockAdmin.On("DescribeConsumerGroups", mock.Anything, []string{"billing"}, mock.Anything).Return(kafka.DescribeConsumerGroupsResult{
+		ConsumerGroupDescriptions: []kafka.ConsumerGroupDescription{{
+			GroupID: "billing",
+			State:   kafka.ConsumerGroupStateStable,
+			Members: []kafka.MemberDescription{{ConsumerID: "consumer-1"}},
+		}},
+	}, nil)
+
+	err = kafAdmin.ResetConsumerGroupOffsets("billing", []OffsetReset{{Topic: "orders", Partition: 0, Target: 10}})
+	assert.ErrorContains(t, err, "1 active members")
+	mockAdmin.AssertNotCalled(t, "AlterConsumerGroupOffsets", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kafctl/internal/logger"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// Timeout for deleting records
+const recordsRequestTimeout = 60 * time.Second
+
+// Modes of a purge
+const (
+	PurgeBeforeOffset = "before-offset"
+	PurgeBeforeTime   = "before-time"
+	PurgeAll          = "all"
+)
+
+// PurgeSpec describes up to where the records of a topic are deleted: before
+// Offset, before the first record at or after Time, or every record. Partition
+// is -1 to purge every partition.
+type PurgeSpec struct {
+	Mode      string
+	Partition int32
+	Offset    int64
+	Time      time.Time
+}
+
+func (s PurgeSpec) String() string {
+	switch s.Mode {
+	case PurgeBeforeOffset:
+		return fmt.Sprintf("%s %d", s.Mode, s.Offset)
+	case PurgeBeforeTime:
+		return fmt.Sprintf("%s %s", s.Mode, s.Time.UTC().Format(time.RFC3339))
+	}
+	return s.Mode
+}
+
+// RecordPurge is the planned purge of one partition. Records from Earliest
+// up to Target are deleted, Latest is the end of the partition.
+type RecordPurge struct {
+	Topic     string
+	Partition int32
+	Earliest  int64
+	Target    int64
+	Latest    int64
+}
+
+// Count is the number of offsets removed. Compacted partitions and
+// transaction markers make it an upper bound of the messages removed.
+func (p RecordPurge) Count() int64 {
+	return p.Target - p.Earliest
+}
+
+// DeleteRecords deletes the records before the offset of every partition.
+// The returned partitions carry the new low watermark as offset, or the
+// error of the partition.
+func (ka *KafAdmin) DeleteRecords(offsets []kafka.TopicPartition) ([]kafka.TopicPartition, error) {
+
+	logger.Info("Deleting records", "partitions", len(offsets))
+
+	ctx, cancel := context.WithTimeout(context.Background(), recordsRequestTimeout)
+	defer cancel()
+
+	result, err := ka.admin.DeleteRecords(ctx, offsets,
+		kafka.SetAdminRequestTimeout(recordsRequestTimeout),
+		kafka.SetAdminOperationTimeout(recordsRequestTimeout))
+	if err != nil {
+		logger.Error("Failed to delete records", "error", err)
+		return nil, err
+	}
+
+	deleted := make([]kafka.TopicPartition, 0, len(result.DeleteRecordsResults))
+	for _, r := range result.DeleteRecordsResults {
+		tp := r.TopicPartition
+		if r.DeletedRecords != nil {
+			tp.Offset = r.DeletedRecords.LowWatermark
+		}
+		deleted = append(deleted, tp)
+	}
+	sortTopicPartitions(deleted)
+	return deleted, nil
+}
+
+// PlanPurge computes earliest, target and latest offset of the partitions
+// selected by spec. Targets are kept between the earliest and latest offset.
+func (ka *KafAdmin) PlanPurge(topic string, spec PurgeSpec) ([]RecordPurge, error) {
+
+	logger.Info("Planning purge", "topic", topic, "partition", spec.Partition, "spec", spec.String())
+
+	all, err := ka.topicPartitions(topic)
+	if err != nil {
+		return nil, err
+	}
+	partitions := all
+	if spec.Partition >= 0 {
+		partitions = nil
+		for _, tp := range all {
+			if tp.Partition == spec.Partition {
+				partitions = append(partitions, tp)
+			}
+		}
+		if len(partitions) == 0 {
+			return nil, fmt.Errorf("topic '%s' has no partition %d", topic, spec.Partition)
+		}
+	}
+
+	earliest, err := ka.offsetsBySpec(partitions, kafka.EarliestOffsetSpec)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := ka.offsetsBySpec(partitions, kafka.LatestOffsetSpec)
+	if err != nil {
+		return nil, err
+	}
+	var byTime map[string]map[int32]int64
+	if spec.Mode == PurgeBeforeTime {
+		byTime, err = ka.offsetsBySpec(partitions, kafka.NewOffsetSpecForTimestamp(spec.Time.UnixMilli()))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	plan := make([]RecordPurge, 0, len(partitions))
+	for _, tp := range partitions {
+		p := tp.Partition
+		low, high := earliest[topic][p], latest[topic][p]
+
+		var target int64
+		switch spec.Mode {
+		case PurgeBeforeOffset:
+			target = spec.Offset
+		case PurgeBeforeTime:
+			// no message at or after the time
+			target = byTime[topic][p]
+			if target < 0 {
+				target = high
+			}
+		case PurgeAll:
+			target = high
+		default:
+			return nil, fmt.Errorf("unknown purge mode '%s'", spec.Mode)
+		}
+		plan = append(plan, RecordPurge{Topic: topic, Partition: p, Earliest: low, Target: min(max(target, low), high), Latest: high})
+	}
+	return plan, nil
+}
+
+// PurgeRecords deletes the records of the plan. Partitions with nothing to
+// delete are skipped.
+func (ka *KafAdmin) PurgeRecords(plan []RecordPurge) error {
+
+	var offsets []kafka.TopicPartition
+	for _, p := range plan {
+		if p.Count() > 0 {
+			topic := p.Topic
+			offsets = append(offsets, kafka.TopicPartition{Topic: &topic, Partition: p.Partition, Offset: kafka.Offset(p.Target)})
+		}
+	}
+	if len(offsets) == 0 {
+		return errors.New("no records to delete")
+	}
+
+	deleted, err := ka.DeleteRecords(offsets)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, tp := range deleted {
+		if tp.Error != nil {
+			errs = append(errs, fmt.Errorf("%s[%d]: %w", *tp.Topic, tp.Partition, tp.Error))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to delete records: %w", errors.Join(errs...))
+	}
+	logger.Info("Deleted records", "partitions", len(deleted))
+	return nil
+}
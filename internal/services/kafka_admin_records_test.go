@@ -0,0 +1,101 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_PlanPurge(t *testing.T) {
+
+	topic := "orders"
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		spec PurgeSpec
+		want []int64
+	}{
+		{"all", PurgeSpec{Mode: PurgeAll, Partition: -1}, []int64{100, 50}},
+		{"before time", PurgeSpec{Mode: PurgeBeforeTime, Partition: -1, Time: at}, []int64{60, 50}},
+		{"before offset clamped", PurgeSpec{Mode: PurgeBeforeOffset, Partition: -1, Offset: 5}, []int64{10, 5}},
+		{"one partition", PurgeSpec{Mode: PurgeBeforeOffset, Partition: 1, Offset: 70}, []int64{50}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kafAdmin, mockAdmin, resetAdmin, err := setup(t)
+			assert.NoError(t, err)
+			defer resetAdmin()
+
+			mockAdmin.On("DescribeTopics", mock.Anything, mock.Anything, mock.Anything).Return(kafka.DescribeTopicsResult{
+				TopicDescriptions: []kafka.TopicDescription{{Name: topic, Partitions: []kafka.TopicPartitionInfo{{Partition: 0}, {Partition: 1}}}},
+			}, nil)
+			mockAdmin.On("ListOffsets", mock.Anything, offsetsFor(kafka.EarliestOffsetSpec), mock.Anything).Return(listOffsetsResult(topic, 10, 0), nil)
+			mockAdmin.On("ListOffsets", mock.Anything, offsetsFor(kafka.LatestOffsetSpec), mock.Anything).Return(listOffsetsResult(topic, 100, 50), nil)
+			mockAdmin.On("ListOffsets", mock.Anything, offsetsFor(kafka.NewOffsetSpecForTimestamp(at.UnixMilli())), mock.Anything).
+				Return(listOffsetsResult(topic, 60, -1), nil)
+
+			plan, err := kafAdmin.PlanPurge(topic, tt.spec)
+			assert.NoError(t, err)
+			var targets []int64
+			for _, p := range plan {
+				targets = append(targets, p.Target)
+			}
+			assert.Equal(t, tt.want, targets)
+		})
+	}
+}
+
+func Test_PlanPurge_UnknownPartition(t *testing.T) {
+
+	kafAdmin, mockAdmin, resetAdmin, err := setup(t)
+	assert.NoError(t, err)
+	defer resetAdmin()
+
+	mockAdmin.On("DescribeTopics", mock.Anything, mock.Anything, mock.Anything).Return(kafka.DescribeTopicsResult{
+		TopicDescriptions: []kafka.TopicDescription{{Name: "orders", Partitions: []kafka.TopicPartitionInfo{{Partition: 0}}}},
+	}, nil)
+
+	_, err = kafAdmin.PlanPurge("orders", PurgeSpec{Mode: PurgeAll, Partition: 3})
+	assert.ErrorContains(t, err, "has no partition 3")
+}
+
+func Test_PurgeRecords(t *testing.T) {
+
+	kafAdmin, mockAdmin, resetAdmin, err := setup(t)
+	assert.NoError(t, err)
+	defer resetAdmin()
+
+	topic := "orders"
+	plan := []RecordPurge{
+		{Topic: topic, Partition: 0, Earliest: 10, Target: 60, Latest: 100},
+		{Topic: topic, Partition: 1, Earliest: 50, Target: 50, Latest: 50},
+	}
+
+	err = kafAdmin.PurgeRecords(plan[1:])
+	assert.ErrorContains(t, err, "no records to delete")
+	mockAdmin.AssertNotCalled(t, "DeleteRecords", mock.Anything, mock.Anything, mock.Anything)
+
+	expected := []kafka.TopicPartition{{Topic: &topic, Partition: 0, Offset: 60}}
+	mockAdmin.On("DeleteRecords", mock.Anything, expected, mock.Anything).Return(kafka.DeleteRecordsResults{
+		DeleteRecordsResults: []kafka.DeleteRecordsResult{{
+			TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 0},
+			DeletedRecords: &kafka.DeletedRecords{LowWatermark: 60},
+		}},
+	}, nil).Once()
+	err = kafAdmin.PurgeRecords(plan)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(50), plan[0].Count())
+
+	mockAdmin.On("DeleteRecords", mock.Anything, mock.Anything, mock.Anything).Return(kafka.DeleteRecordsResults{
+		DeleteRecordsResults: []kafka.DeleteRecordsResult{{
+			TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 0, Error: kafka.NewError(kafka.ErrPolicyViolation, "compacted topic", false)},
+		}},
+	}, nil)
+	err = kafAdmin.PurgeRecords(plan)
+	assert.ErrorContains(t, err, "compacted topic")
+}
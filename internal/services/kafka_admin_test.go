@@ -0,0 +1,58 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SetSSLConfig_SaslScram(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "security.json")
+	err := os.WriteFile(file, []byte(`{"securityProtocol": "SASL_SSL", "sslCaLocation": "ca.pem",
+		"saslMechanism": "scram-sha-512", "saslUsername": "billing", "saslPassword": "secret"}`), 0o600)
+	assert.NoError(t, err)
+
+	cfg := &kafka.ConfigMap{}
+	assert.NoError(t, SetSSLConfig(cfg, file))
+	assert.Equal(t, kafka.ConfigMap{
+		"security.protocol": "SASL_SSL",
+		"ssl.ca.location":   "ca.pem",
+		"sasl.mechanism":    "SCRAM-SHA-512",
+		"sasl.username":     "billing",
+		"sasl.password":     "secret",
+	}, *cfg)
+}
+
+func Test_SSLConfig_Apply(t *testing.T) {
+
+	cfg := &kafka.ConfigMap{}
+	err := SSLConfig{SecurityProtocol: "SASL_PLAINTEXT", SaslMechanism: SaslOAuthBearer, OAuthTokenEndpoint: "https://idp/token",
+		OAuthClientId: "kafctl", OAuthClientSecret: "secret", OAuthScope: "kafka"}.Apply(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, kafka.ConfigMap{
+		"security.protocol":                   "SASL_PLAINTEXT",
+		"sasl.mechanism":                      "OAUTHBEARER",
+		"sasl.oauthbearer.method":             "oidc",
+		"sasl.oauthbearer.token.endpoint.url": "https://idp/token",
+		"sasl.oauthbearer.client.id":          "kafctl",
+		"sasl.oauthbearer.client.secret":      "secret",
+		"sasl.oauthbearer.scope":              "kafka",
+	}, *cfg)
+
+	cfg = &kafka.ConfigMap{}
+	assert.NoError(t, SSLConfig{SecurityProtocol: "SSL", SslCaLocation: "ca.pem", SaslMechanism: SaslPlain}.Apply(cfg))
+	assert.Equal(t, kafka.ConfigMap{"security.protocol": "SSL", "ssl.ca.location": "ca.pem"}, *cfg)
+
+	for _, c := range []SSLConfig{
+		{SecurityProtocol: "SASL_SSL"},
+		{SecurityProtocol: "SASL_SSL", SaslMechanism: "GSSAPI"},
+		{SecurityProtocol: "SASL_SSL", SaslMechanism: SaslPlain, SaslUsername: "billing"},
+		{SecurityProtocol: "SASL_SSL", SaslMechanism: SaslOAuthBearer, OAuthClientId: "kafctl"},
+	} {
+		assert.Error(t, c.Apply(&kafka.ConfigMap{}), "%+v", c)
+	}
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kafctl/internal/logger"
+	"sort"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// Timeout for SCRAM credential admin requests
+const userRequestTimeout = 30 * time.Second
+
+// DefaultScramIterations is used for new credentials when none are given.
+// Kafka accepts between 4096 and 16384.
+const DefaultScramIterations = 8192
+
+// ScramMechanisms are the mechanisms a credential can be stored for
+var ScramMechanisms = []string{SaslScramSha256, SaslScramSha512}
+
+// DescribeUserScramCredentials returns the SCRAM credentials of the users, or
+// of every user with credentials when users is empty, sorted by user. Unknown
+// users come back with ErrResourceNotFound as their error.
+func (ka *KafAdmin) DescribeUserScramCredentials(users []string) ([]kafka.UserScramCredentialsDescription, error) {
+
+	logger.Info("Describing SCRAM credentials", "users", users)
+
+	ctx, cancel := context.WithTimeout(context.Background(), userRequestTimeout)
+	defer cancel()
+
+	result, err := ka.admin.DescribeUserScramCredentials(ctx, users, kafka.SetAdminRequestTimeout(userRequestTimeout))
+	if err != nil {
+		logger.Error("Failed to describe SCRAM credentials", "error", err)
+		return nil, err
+	}
+
+	descriptions := make([]kafka.UserScramCredentialsDescription, 0, len(result.Descriptions))
+	for _, d := range result.Descriptions {
+		sort.Slice(d.ScramCredentialInfos, func(i, j int) bool {
+			return d.ScramCredentialInfos[i].Mechanism < d.ScramCredentialInfos[j].Mechanism
+		})
+		descriptions = append(descriptions, d)
+	}
+	sort.Slice(descriptions, func(i, j int) bool { return descriptions[i].User < descriptions[j].User })
+	return descriptions, nil
+}
+
+// UpsertUserScramCredential creates or replaces the credential of the user
+// for the mechanism. The password is only sent to the broker, never logged.
+func (ka *KafAdmin) UpsertUserScramCredential(user, mechanism string, iterations int, password []byte) error {
+
+	m, err := kafka.ScramMechanismFromString(mechanism)
+	if err != nil {
+		return fmt.Errorf("unknown SCRAM mechanism '%s', expected %s or %s", mechanism, SaslScramSha256, SaslScramSha512)
+	}
+	if user == "" {
+		return errors.New("user is required")
+	}
+	if len(password) == 0 {
+		return errors.New("password must not be empty")
+	}
+	if iterations == 0 {
+		iterations = DefaultScramIterations
+	}
+
+	logger.Info("Upserting SCRAM credential", "user", user, "mechanism", m, "iterations", iterations)
+	return ka.alterUserScramCredentials(user, []kafka.UserScramCredentialUpsertion{{
+		User:                user,
+		ScramCredentialInfo: kafka.ScramCredentialInfo{Mechanism: m, Iterations: iterations},
+		Password:            password,
+	}}, nil)
+}
+
+// DeleteUserScramCredential deletes the credential of the user for the
+// mechanism.
+func (ka *KafAdmin) DeleteUserScramCredential(user, mechanism string) error {
+
+	m, err := kafka.ScramMechanismFromString(mechanism)
+	if err != nil {
+		return fmt.Errorf("unknown SCRAM mechanism '%s', expected %s or %s", mechanism, SaslScramSha256, SaslScramSha512)
+	}
+
+	logger.Info("Deleting SCRAM credential", "user", user, "mechanism", m)
+	return ka.alterUserScramCredentials(user, nil, []kafka.UserScramCredentialDeletion{{User: user, Mechanism: m}})
+}
+
+func (ka *KafAdmin) alterUserScramCredentials(user string, upsertions []kafka.UserScramCredentialUpsertion,
+	deletions []kafka.UserScramCredentialDeletion) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), userRequestTimeout)
+	defer cancel()
+
+	result, err := ka.admin.AlterUserScramCredentials(ctx, upsertions, deletions, kafka.SetAdminRequestTimeout(userRequestTimeout))
+	if err != nil {
+		logger.Error("Failed to alter SCRAM credentials", "user", user, "error", err)
+		return err
+	}
+	if e, ok := result.Errors[user]; ok && e.Code() != kafka.ErrNoError {
+		return fmt.Errorf("altering SCRAM credentials of user '%s': %w", user, e)
+	}
+	return nil
+}
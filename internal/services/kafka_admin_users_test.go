@@ -0,0 +1,65 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_DescribeUserScramCredentials(t *testing.T) {
+
+	kafAdmin, mockAdmin, resetAdmin, err := setup(t)
+	assert.NoError(t, err)
+	defer resetAdmin()
+
+	sha256 := kafka.ScramCredentialInfo{Mechanism: kafka.ScramMechanismSHA256, Iterations: 4096}
+	sha512 := kafka.ScramCredentialInfo{Mechanism: kafka.ScramMechanismSHA512, Iterations: 8192}
+	mockAdmin.On("DescribeUserScramCredentials", mock.Anything, []string(nil), mock.Anything).
+		Return(kafka.DescribeUserScramCredentialsResult{Descriptions: map[string]kafka.UserScramCredentialsDescription{
+			"shipping": {User: "shipping", ScramCredentialInfos: []kafka.ScramCredentialInfo{sha512, sha256}},
+			"billing":  {User: "billing", ScramCredentialInfos: []kafka.ScramCredentialInfo{sha512}},
+		}}, nil)
+
+	users, err := kafAdmin.DescribeUserScramCredentials(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []kafka.UserScramCredentialsDescription{
+		{User: "billing", ScramCredentialInfos: []kafka.ScramCredentialInfo{sha512}},
+		{User: "shipping", ScramCredentialInfos: []kafka.ScramCredentialInfo{sha256, sha512}},
+	}, users)
+}
+
+func Test_UpsertUserScramCredential(t *testing.T) {
+
+	kafAdmin, mockAdmin, resetAdmin, err := setup(t)
+	assert.NoError(t, err)
+	defer resetAdmin()
+
+	expected := []kafka.UserScramCredentialUpsertion{{
+		User:                "billing",
+		ScramCredentialInfo: kafka.ScramCredentialInfo{Mechanism: kafka.ScramMechanismSHA512, Iterations: DefaultScramIterations},
+		Password:            []byte("secret"),
+	}}
+	mockAdmin.On("AlterUserScramCredentials", mock.Anything, expected, []kafka.UserScramCredentialDeletion(nil), mock.Anything).
+		Return(kafka.AlterUserScramCredentialsResult{Errors: map[string]kafka.Error{"billing": kafka.NewError(kafka.ErrNoError, "", false)}}, nil)
+
+	assert.NoError(t, kafAdmin.UpsertUserScramCredential("billing", "scram-sha-512", 0, []byte("secret")))
+	assert.ErrorContains(t, kafAdmin.UpsertUserScramCredential("billing", "SCRAM-SHA-1", 0, []byte("secret")), "unknown SCRAM mechanism")
+	assert.ErrorContains(t, kafAdmin.UpsertUserScramCredential("billing", SaslScramSha512, 0, nil), "password")
+}
+
+func Test_DeleteUserScramCredential(t *testing.T) {
+
+	kafAdmin, mockAdmin, resetAdmin, err := setup(t)
+	assert.NoError(t, err)
+	defer resetAdmin()
+
+	mockAdmin.On("AlterUserScramCredentials", mock.Anything, []kafka.UserScramCredentialUpsertion(nil),
+		[]kafka.UserScramCredentialDeletion{{User: "billing", Mechanism: kafka.ScramMechanismSHA256}}, mock.Anything).
+		Return(kafka.AlterUserScramCredentialsResult{Errors: map[string]kafka.Error{
+			"billing": kafka.NewError(kafka.ErrResourceNotFound, "no credential", false)}}, nil)
+
+	err = kafAdmin.DeleteUserScramCredential("billing", SaslScramSha256)
+	assert.ErrorContains(t, err, "no credential")
+}
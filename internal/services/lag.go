@@ -0,0 +1,252 @@
+package services
+
+import (
+	"fmt"
+	"kafctl/internal/logger"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// Sort keys for lag reports.
+const (
+	LagSortLag       = "lag"
+	LagSortGroup     = "group"
+	LagSortTopic     = "topic"
+	LagSortPartition = "partition"
+)
+
+var LagSortKeys = []string{LagSortLag, LagSortGroup, LagSortTopic, LagSortPartition}
+
+// PartitionLag is how far a group is behind on one partition. Committed is
+// -1 when the group has not committed an offset for the partition, its lag
+// then counts every message still retained.
+type PartitionLag struct {
+	Group       string
+	Topic       string
+	Partition   int32
+	Committed   int64
+	StartOffset int64
+	EndOffset   int64
+	Lag         int64
+}
+
+// TopicLag sums the lag of a group over the partitions of a topic.
+type TopicLag struct {
+	Group      string
+	Topic      string
+	Partitions int
+	Lag        int64
+}
+
+// GroupLag sums the lag of a group over all partitions it committed to.
+type GroupLag struct {
+	Group      string
+	Topics     int
+	Partitions int
+	Lag        int64
+}
+
+// LagService combines committed consumer group offsets with the high
+// watermarks of the partitions.
+type LagService struct {
+	admin    IKafAdmin
+	consumer IConsumer
+}
+
+func NewLagService(admin IKafAdmin, consumer IConsumer) *LagService {
+	return &LagService{admin: admin, consumer: consumer}
+}
+
+// GroupLag returns the lag per partition of the groups, restricted to topic
+// unless it is empty.
+func (ls *LagService) GroupLag(groups []string, topic string) ([]PartitionLag, error) {
+
+	watermarks := make(map[string]map[int32]TopicDetails)
+	var lags []PartitionLag
+
+	for _, group := range groups {
+		offsets, err := ls.admin.ListConsumerGroupOffsets(group)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list offsets of group '%s': %w", group, err)
+		}
+
+		for _, tp := range offsets {
+			if tp.Topic == nil || (topic != "" && *tp.Topic != topic) {
+				continue
+			}
+			if tp.Error != nil {
+				logger.Warn("Skipping partition with offset error", "group", group, "topic", *tp.Topic, "partition", tp.Partition, "error", tp.Error)
+				continue
+			}
+
+			marks, ok := watermarks[*tp.Topic]
+			if !ok {
+				marks, err = ls.topicWatermarks(*tp.Topic)
+				if err != nil {
+					return nil, err
+				}
+				watermarks[*tp.Topic] = marks
+			}
+			wm, ok := marks[tp.Partition]
+			if !ok {
+				logger.Warn("No watermarks for partition", "topic", *tp.Topic, "partition", tp.Partition)
+				continue
+			}
+			lags = append(lags, newPartitionLag(group, *tp.Topic, tp.Partition, tp.Offset, wm))
+		}
+	}
+
+	logger.Info("Computed consumer lag", "groups", len(groups), "topic", topic, "partitions", len(lags))
+	SortPartitionLags(lags, LagSortLag, true)
+	return lags, nil
+}
+
+// TopicLag returns the lag per partition of every group that committed
+// offsets for the topic.
+func (ls *LagService) TopicLag(topic string) ([]PartitionLag, error) {
+	listings, err := ls.admin.ListConsumerGroups()
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]string, 0, len(listings))
+	for _, g := range listings {
+		groups = append(groups, g.GroupID)
+	}
+	return ls.GroupLag(groups, topic)
+}
+
+func (ls *LagService) topicWatermarks(topic string) (map[int32]TopicDetails, error) {
+	details, err := ls.consumer.GetMessagesInfo(topic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get watermarks of topic '%s': %w", topic, err)
+	}
+	marks := make(map[int32]TopicDetails, len(details))
+	for _, d := range details {
+		marks[d.PartitionId] = d
+	}
+	return marks, nil
+}
+
+func newPartitionLag(group, topic string, partition int32, committed kafka.Offset, wm TopicDetails) PartitionLag {
+	lag := PartitionLag{
+		Group:       group,
+		Topic:       topic,
+		Partition:   partition,
+		Committed:   int64(committed),
+		StartOffset: wm.StartOffset,
+		EndOffset:   wm.EndOffset,
+	}
+	if committed < 0 {
+		lag.Committed = -1
+		lag.Lag = wm.EndOffset - wm.StartOffset
+	} else {
+		lag.Lag = max(0, wm.EndOffset-max(int64(committed), wm.StartOffset))
+	}
+	return lag
+}
+
+// FilterPartitionLags keeps the partitions with a lag of at least minLag.
+func FilterPartitionLags(lags []PartitionLag, minLag int64) []PartitionLag {
+	return slices.DeleteFunc(slices.Clone(lags), func(l PartitionLag) bool { return l.Lag < minLag })
+}
+
+// SortPartitionLags sorts by one of LagSortKeys, ties are broken by group,
+// topic and partition in ascending order.
+func SortPartitionLags(lags []PartitionLag, sortBy string, descending bool) {
+	sort.SliceStable(lags, func(i, j int) bool {
+		a, b := lags[i], lags[j]
+		var cmp int
+		switch sortBy {
+		case LagSortLag:
+			cmp = compareInt64(a.Lag, b.Lag)
+		case LagSortGroup:
+			cmp = strings.Compare(a.Group, b.Group)
+		case LagSortTopic:
+			cmp = strings.Compare(a.Topic, b.Topic)
+		case LagSortPartition:
+			cmp = compareInt64(int64(a.Partition), int64(b.Partition))
+		}
+		if descending {
+			cmp = -cmp
+		}
+		if cmp != 0 {
+			return cmp < 0
+		}
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		if a.Topic != b.Topic {
+			return a.Topic < b.Topic
+		}
+		return a.Partition < b.Partition
+	})
+}
+
+// LagByTopic sums partition lags per group and topic, largest lag first.
+func LagByTopic(lags []PartitionLag) []TopicLag {
+	index := make(map[[2]string]int)
+	var res []TopicLag
+	for _, l := range lags {
+		key := [2]string{l.Group, l.Topic}
+		i, ok := index[key]
+		if !ok {
+			i = len(res)
+			index[key] = i
+			res = append(res, TopicLag{Group: l.Group, Topic: l.Topic})
+		}
+		res[i].Partitions++
+		res[i].Lag += l.Lag
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Lag != res[j].Lag {
+			return res[i].Lag > res[j].Lag
+		}
+		if res[i].Group != res[j].Group {
+			return res[i].Group < res[j].Group
+		}
+		return res[i].Topic < res[j].Topic
+	})
+	return res
+}
+
+// LagByGroup sums partition lags per group, largest lag first.
+func LagByGroup(lags []PartitionLag) []GroupLag {
+	index := make(map[string]int)
+	topics := make(map[string]map[string]bool)
+	var res []GroupLag
+	for _, l := range lags {
+		i, ok := index[l.Group]
+		if !ok {
+			i = len(res)
+			index[l.Group] = i
+			topics[l.Group] = make(map[string]bool)
+			res = append(res, GroupLag{Group: l.Group})
+		}
+		topics[l.Group][l.Topic] = true
+		res[i].Partitions++
+		res[i].Lag += l.Lag
+	}
+	for i := range res {
+		res[i].Topics = len(topics[res[i].Group])
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Lag != res[j].Lag {
+			return res[i].Lag > res[j].Lag
+		}
+		return res[i].Group < res[j].Group
+	})
+	return res
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
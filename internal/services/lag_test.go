@@ -0,0 +1,105 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// watermarkConsumer answers GetMessagesInfo from a fixed map and counts calls
+type watermarkConsumer struct {
+	IConsumer
+	details map[string][]TopicDetails
+	calls   map[string]int
+}
+
+func (wc *watermarkConsumer) GetMessagesInfo(topic string) ([]TopicDetails, error) {
+	wc.calls[topic]++
+	return wc.details[topic], nil
+}
+
+func Test_GroupLag(t *testing.T) {
+
+	kafAdmin, mockAdmin, resetAdmin, err := setup(t)
+	assert.NoError(t, err)
+	defer resetAdmin()
+
+	orders, payments := "orders", "payments"
+	offsets := func(group string, tps ...kafka.TopicPartition) kafka.ListConsumerGroupOffsetsResult {
+		return kafka.ListConsumerGroupOffsetsResult{
+			ConsumerGroupsTopicPartitions: []kafka.ConsumerGroupTopicPartitions{{Group: group, Partitions: tps}},
+		}
+	}
+	mockAdmin.On("ListConsumerGroupOffsets", mock.Anything, []kafka.ConsumerGroupTopicPartitions{{Group: "billing"}}, mock.Anything).
+		Return(offsets("billing",
+			kafka.TopicPartition{Topic: &orders, Partition: 0, Offset: 90},
+			kafka.TopicPartition{Topic: &orders, Partition: 1, Offset: kafka.OffsetInvalid},
+			kafka.TopicPartition{Topic: &payments, Partition: 0, Offset: 5}), nil)
+	mockAdmin.On("ListConsumerGroupOffsets", mock.Anything, []kafka.ConsumerGroupTopicPartitions{{Group: "audit"}}, mock.Anything).
+		Return(offsets("audit",
+			kafka.TopicPartition{Topic: &orders, Partition: 0, Offset: 100},
+			kafka.TopicPartition{Topic: &orders, Partition: 1, Offset: 2}), nil)
+
+	consumer := &watermarkConsumer{
+		details: map[string][]TopicDetails{
+			orders: {
+				{PartitionId: 0, StartOffset: 0, EndOffset: 100},
+				{PartitionId: 1, StartOffset: 10, EndOffset: 30},
+			},
+			payments: {{PartitionId: 0, StartOffset: 0, EndOffset: 5}},
+		},
+		calls: map[string]int{},
+	}
+
+	lags, err := NewLagService(kafAdmin, consumer).GroupLag([]string{"billing", "audit"}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []PartitionLag{
+		// offsets below the log start count from the start offset
+		{Group: "audit", Topic: orders, Partition: 1, Committed: 2, StartOffset: 10, EndOffset: 30, Lag: 20},
+		{Group: "billing", Topic: orders, Partition: 1, Committed: -1, StartOffset: 10, EndOffset: 30, Lag: 20},
+		{Group: "billing", Topic: orders, Partition: 0, Committed: 90, StartOffset: 0, EndOffset: 100, Lag: 10},
+		{Group: "audit", Topic: orders, Partition: 0, Committed: 100, StartOffset: 0, EndOffset: 100, Lag: 0},
+		{Group: "billing", Topic: payments, Partition: 0, Committed: 5, StartOffset: 0, EndOffset: 5, Lag: 0},
+	}, lags)
+	assert.Equal(t, 1, consumer.calls[orders], "watermarks are fetched once per topic")
+
+	lags, err = NewLagService(kafAdmin, consumer).GroupLag([]string{"billing"}, payments)
+	assert.NoError(t, err)
+	assert.Len(t, lags, 1)
+	assert.Equal(t, payments, lags[0].Topic)
+}
+
+func Test_LagSummaries(t *testing.T) {
+
+	lags := []PartitionLag{
+		{Group: "billing", Topic: "orders", Partition: 0, Lag: 10},
+		{Group: "billing", Topic: "orders", Partition: 1, Lag: 5},
+		{Group: "billing", Topic: "payments", Partition: 0, Lag: 1},
+		{Group: "audit", Topic: "orders", Partition: 0, Lag: 40},
+	}
+
+	assert.Equal(t, []TopicLag{
+		{Group: "audit", Topic: "orders", Partitions: 1, Lag: 40},
+		{Group: "billing", Topic: "orders", Partitions: 2, Lag: 15},
+		{Group: "billing", Topic: "payments", Partitions: 1, Lag: 1},
+	}, LagByTopic(lags))
+
+	assert.Equal(t, []GroupLag{
+		{Group: "audit", Topics: 1, Partitions: 1, Lag: 40},
+		{Group: "billing", Topics: 2, Partitions: 3, Lag: 16},
+	}, LagByGroup(lags))
+
+	filtered := FilterPartitionLags(lags, 5)
+	assert.Len(t, filtered, 3)
+	assert.Len(t, lags, 4, "filtering leaves the input untouched")
+
+	SortPartitionLags(lags, LagSortPartition, false)
+	assert.Equal(t, []int32{0, 0, 0, 1}, []int32{lags[0].Partition, lags[1].Partition, lags[2].Partition, lags[3].Partition})
+	assert.Equal(t, "audit", lags[0].Group)
+
+	SortPartitionLags(lags, LagSortGroup, true)
+	assert.Equal(t, "billing", lags[0].Group)
+	assert.Equal(t, "audit", lags[3].Group)
+}
@@ -44,3 +44,94 @@ func (m *MockAdminClient) ListOffsets(ctx context.Context, topicPartitionOffsets
 	args := m.Called(ctx, topicPartitionOffsets, options)
 	return args.Get(0).(kafka.ListOffsetsResult), args.Error(1)
 }
+
+func (m *MockAdminClient) ListConsumerGroups(ctx context.Context,
+	options ...kafka.ListConsumerGroupsAdminOption) (result kafka.ListConsumerGroupsResult, err error) {
+	args := m.Called(ctx, options)
+	return args.Get(0).(kafka.ListConsumerGroupsResult), args.Error(1)
+}
+
+func (m *MockAdminClient) DescribeConsumerGroups(ctx context.Context, groups []string,
+	options ...kafka.DescribeConsumerGroupsAdminOption) (result kafka.DescribeConsumerGroupsResult, err error) {
+	args := m.Called(ctx, groups, options)
+	return args.Get(0).(kafka.DescribeConsumerGroupsResult), args.Error(1)
+}
+
+func (m *MockAdminClient) ListConsumerGroupOffsets(ctx context.Context, groupsPartitions []kafka.ConsumerGroupTopicPartitions,
+	options ...kafka.ListConsumerGroupOffsetsAdminOption) (lcgor kafka.ListConsumerGroupOffsetsResult, err error) {
+	args := m.Called(ctx, groupsPartitions, options)
+	return args.Get(0).(kafka.ListConsumerGroupOffsetsResult), args.Error(1)
+}
+
+func (m *MockAdminClient) AlterConsumerGroupOffsets(ctx context.Context, groupsPartitions []kafka.ConsumerGroupTopicPartitions,
+	options ...kafka.AlterConsumerGroupOffsetsAdminOption) (acgor kafka.AlterConsumerGroupOffsetsResult, err error) {
+	args := m.Called(ctx, groupsPartitions, options)
+	return args.Get(0).(kafka.AlterConsumerGroupOffsetsResult), args.Error(1)
+}
+
+func (m *MockAdminClient) DeleteConsumerGroups(ctx context.Context, groups []string,
+	options ...kafka.DeleteConsumerGroupsAdminOption) (result kafka.DeleteConsumerGroupsResult, err error) {
+	args := m.Called(ctx, groups, options)
+	return args.Get(0).(kafka.DeleteConsumerGroupsResult), args.Error(1)
+}
+
+func (m *MockAdminClient) DescribeConfigs(ctx context.Context, resources []kafka.ConfigResource,
+	options ...kafka.DescribeConfigsAdminOption) (result []kafka.ConfigResourceResult, err error) {
+	args := m.Called(ctx, resources, options)
+	return args.Get(0).([]kafka.ConfigResourceResult), args.Error(1)
+}
+
+func (m *MockAdminClient) IncrementalAlterConfigs(ctx context.Context, resources []kafka.ConfigResource,
+	options ...kafka.AlterConfigsAdminOption) (result []kafka.ConfigResourceResult, err error) {
+	args := m.Called(ctx, resources, options)
+	return args.Get(0).([]kafka.ConfigResourceResult), args.Error(1)
+}
+
+func (m *MockAdminClient) CreatePartitions(ctx context.Context, partitions []kafka.PartitionsSpecification,
+	options ...kafka.CreatePartitionsAdminOption) (result []kafka.TopicResult, err error) {
+	args := m.Called(ctx, partitions, options)
+	return args.Get(0).([]kafka.TopicResult), args.Error(1)
+}
+
+func (m *MockAdminClient) DescribeCluster(ctx context.Context,
+	options ...kafka.DescribeClusterAdminOption) (result kafka.DescribeClusterResult, err error) {
+	args := m.Called(ctx, options)
+	return args.Get(0).(kafka.DescribeClusterResult), args.Error(1)
+}
+
+func (m *MockAdminClient) CreateACLs(ctx context.Context, aclBindings kafka.ACLBindings,
+	options ...kafka.CreateACLsAdminOption) (result []kafka.CreateACLResult, err error) {
+	args := m.Called(ctx, aclBindings, options)
+	return args.Get(0).([]kafka.CreateACLResult), args.Error(1)
+}
+
+func (m *MockAdminClient) DescribeACLs(ctx context.Context, aclBindingFilter kafka.ACLBindingFilter,
+	options ...kafka.DescribeACLsAdminOption) (result *kafka.DescribeACLsResult, err error) {
+	args := m.Called(ctx, aclBindingFilter, options)
+	return args.Get(0).(*kafka.DescribeACLsResult), args.Error(1)
+}
+
+func (m *MockAdminClient) DeleteACLs(ctx context.Context, aclBindingFilters kafka.ACLBindingFilters,
+	options ...kafka.DeleteACLsAdminOption) (result []kafka.DeleteACLsResult, err error) {
+	args := m.Called(ctx, aclBindingFilters, options)
+	return args.Get(0).([]kafka.DeleteACLsResult), args.Error(1)
+}
+
+func (m *MockAdminClient) DescribeUserScramCredentials(ctx context.Context, users []string,
+	options ...kafka.DescribeUserScramCredentialsAdminOption) (result kafka.DescribeUserScramCredentialsResult, err error) {
+	args := m.Called(ctx, users, options)
+	return args.Get(0).(kafka.DescribeUserScramCredentialsResult), args.Error(1)
+}
+
+func (m *MockAdminClient) AlterUserScramCredentials(ctx context.Context, upsertions []kafka.UserScramCredentialUpsertion,
+	deletions []kafka.UserScramCredentialDeletion,
+	options ...kafka.AlterUserScramCredentialsAdminOption) (result kafka.AlterUserScramCredentialsResult, err error) {
+	args := m.Called(ctx, upsertions, deletions, options)
+	return args.Get(0).(kafka.AlterUserScramCredentialsResult), args.Error(1)
+}
+
+func (m *MockAdminClient) DeleteRecords(ctx context.Context, recordsToDelete []kafka.TopicPartition,
+	options ...kafka.DeleteRecordsAdminOption) (result kafka.DeleteRecordsResults, err error) {
+	args := m.Called(ctx, recordsToDelete, options)
+	return args.Get(0).(kafka.DeleteRecordsResults), args.Error(1)
+}
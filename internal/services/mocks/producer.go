@@ -0,0 +1,51 @@
+package mocks
+
+import (
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockProducer is a mock implementation of the IRdProducer interface. Delivery
+// reports are sent on EventsChan, which Close closes like kafka.Producer.
+type MockProducer struct {
+	mock.Mock
+	EventsChan chan kafka.Event
+}
+
+func NewMockProducer() *MockProducer {
+	return &MockProducer{EventsChan: make(chan kafka.Event, 100)}
+}
+
+func (m *MockProducer) GetMetadata(topic *string, allTopics bool, timeoutMs int) (*kafka.Metadata, error) {
+	args := m.Called(topic, allTopics, timeoutMs)
+	return args.Get(0).(*kafka.Metadata), args.Error(1)
+}
+
+func (m *MockProducer) Produce(msg *kafka.Message, deliveryChan chan kafka.Event) error {
+	args := m.Called(msg, deliveryChan)
+	return args.Error(0)
+}
+
+func (m *MockProducer) Events() chan kafka.Event {
+	return m.EventsChan
+}
+
+func (m *MockProducer) Flush(timeoutMs int) int {
+	args := m.Called(timeoutMs)
+	return args.Int(0)
+}
+
+func (m *MockProducer) Purge(flags int) error {
+	args := m.Called(flags)
+	return args.Error(0)
+}
+
+func (m *MockProducer) Len() int {
+	args := m.Called()
+	return args.Int(0)
+}
+
+func (m *MockProducer) Close() {
+	m.Called()
+	close(m.EventsChan)
+}
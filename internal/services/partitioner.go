@@ -0,0 +1,79 @@
+package services
+
+import (
+	"fmt"
+	"hash/crc32"
+	"strings"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// Partitioners that pick the partition of a keyed message. Murmur2 matches
+// the default partitioner of the Java client, consistent-random is the
+// default of librdkafka.
+const (
+	PartitionerMurmur2          = "murmur2"
+	PartitionerConsistentRandom = "consistent-random"
+)
+
+var Partitioners = []string{PartitionerMurmur2, PartitionerConsistentRandom}
+
+// Murmur2 is the 32 bit murmur2 hash of the Java client.
+func Murmur2(data []byte) int32 {
+	const (
+		seed uint32 = 0x9747b28c
+		m    uint32 = 0x5bd1e995
+		r           = 24
+	)
+
+	length := len(data)
+	h := seed ^ uint32(length)
+	for i := 0; i+4 <= length; i += 4 {
+		k := uint32(data[i]) | uint32(data[i+1])<<8 | uint32(data[i+2])<<16 | uint32(data[i+3])<<24
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+	}
+
+	tail := data[length&^3:]
+	switch len(tail) {
+	case 3:
+		h ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(tail[0])
+		h *= m
+	}
+
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return int32(h)
+}
+
+// PartitionForKey returns the partition the partitioner picks for key among
+// partitions. Keys the partitioner spreads randomly, a nil key for murmur2
+// and nil or empty keys for consistent-random, get kafka.PartitionAny.
+func PartitionForKey(partitioner string, key []byte, partitions int32) (int32, error) {
+	if partitions <= 0 {
+		return 0, fmt.Errorf("invalid partition count %d", partitions)
+	}
+	switch partitioner {
+	case PartitionerMurmur2:
+		if key == nil {
+			return kafka.PartitionAny, nil
+		}
+		return (Murmur2(key) & 0x7fffffff) % partitions, nil
+	case PartitionerConsistentRandom:
+		if len(key) == 0 {
+			return kafka.PartitionAny, nil
+		}
+		return int32(crc32.ChecksumIEEE(key) % uint32(partitions)), nil
+	}
+	return 0, fmt.Errorf("unknown partitioner '%s', expected one of %s", partitioner, strings.Join(Partitioners, ", "))
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Murmur2(t *testing.T) {
+
+	// Values of the Java client
+	cases := map[string]int32{
+		"21":                         -973932308,
+		"foobar":                     -790332482,
+		"a-little-bit-long-string":   -985981536,
+		"a-little-bit-longer-string": -1486304829,
+		"lkjh234lh9fiuh90y23oiuhsafujhadof229phr9h19h89h8": -58897971,
+		"abc": 479470107,
+	}
+	for key, want := range cases {
+		assert.Equal(t, want, Murmur2([]byte(key)), key)
+	}
+}
+
+func Test_PartitionForKey(t *testing.T) {
+
+	p, err := PartitionForKey(PartitionerMurmur2, []byte("21"), 6)
+	assert.NoError(t, err)
+	assert.Equal(t, int32((-973932308&0x7fffffff)%6), p)
+
+	p, err = PartitionForKey(PartitionerMurmur2, []byte{}, 6)
+	assert.NoError(t, err)
+	assert.NotEqual(t, kafka.PartitionAny, p)
+
+	for _, partitioner := range Partitioners {
+		p, err = PartitionForKey(partitioner, nil, 6)
+		assert.NoError(t, err)
+		assert.Equal(t, kafka.PartitionAny, p, partitioner)
+	}
+
+	p, err = PartitionForKey(PartitionerConsistentRandom, []byte("abc"), 6)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(0x352441c2%6), p)
+
+	_, err = PartitionForKey("round-robin", []byte("abc"), 6)
+	assert.ErrorContains(t, err, "unknown partitioner")
+}
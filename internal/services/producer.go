@@ -1,88 +1,459 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log/slog"
-	"strings"
+	"io"
+	"kafctl/internal/logger"
+	"sync"
 	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 )
 
+const (
+	// Time messages wait in the local queue so they are sent in batches
+	producerLingerMs = 5
+	// Time after which librdkafka fails a message it could not deliver
+	producerMessageTimeout = 8 * time.Second
+	// Max time to wait for the delivery report of a single message, longer
+	// than the message timeout so the report carries the real error
+	deliveryTimeout = producerMessageTimeout + 2*time.Second
+	// Max time to wait for queued messages when the producer is closed
+	producerFlushTimeout = 15 * time.Second
+	// How long to wait for the local queue to drain when it is full
+	producerQueueFullWaitMs = 100
+	// How long partition counts of topics are cached for partitioners
+	partitionCountTTL = 30 * time.Second
+)
+
+// IRdProducer is the part of kafka.Producer used by KafProducer.
+type IRdProducer interface {
+	GetMetadata(topic *string, allTopics bool, timeoutMs int) (*kafka.Metadata, error)
+	Produce(msg *kafka.Message, deliveryChan chan kafka.Event) error
+	Events() chan kafka.Event
+	Flush(timeoutMs int) int
+	Purge(flags int) error
+	Len() int
+	Close()
+}
+
+// DeliveryResult is the delivery report of one message.
+type DeliveryResult struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Error     error
+}
+
+// MessageSpec describes a message to publish. A nil Key sends the message
+// without a key. Partition must be set to kafka.PartitionAny unless the
+// message goes to a fixed partition, and Partitioner picks the partition from the key instead
+// of the default partitioner of librdkafka. A zero Timestamp means now.
+type MessageSpec struct {
+	Topic       string
+	Key         []byte
+	Value       []byte
+	Headers     []kafka.Header
+	Partition   int32
+	Partitioner string
+	Timestamp   time.Time
+}
+
+type IKafProducer interface {
+	NewMessage(spec MessageSpec) (*kafka.Message, error)
+	ProduceAsync(msg *kafka.Message, done func(DeliveryResult)) error
+	Produce(msg *kafka.Message) (DeliveryResult, error)
+	Flush(timeout time.Duration) int
+	Close()
+}
+
+// KafProducer is a producer shared for the life of the app. Messages are
+// batched by librdkafka and every delivery report is handed to the callback
+// of its message by a single delivery goroutine.
+type KafProducer struct {
+	producer IRdProducer
+	// mu guards closed, Close waits for running ProduceAsync calls
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+
+	countsMu sync.Mutex
+	counts   map[string]partitionCount
+}
+
+type partitionCount struct {
+	count   int32
+	fetched time.Time
+}
+
+var NewKafProducer = CreateKafProducer
+
+var (
+	kafkaProducerInstance IKafProducer
+	kafkaProducerMu       sync.Mutex
+)
+
+// CreateKafProducer returns the shared producer, creating it on first use.
+func CreateKafProducer() (IKafProducer, error) {
+
+	kafkaProducerMu.Lock()
+	defer kafkaProducerMu.Unlock()
+
+	if kafkaProducerInstance == nil {
+		producer, err := NewProducer()
+		if err != nil {
+			return nil, err
+		}
+		kafkaProducerInstance = NewKafProducerFrom(producer)
+	}
+	return kafkaProducerInstance, nil
+}
+
+// CloseKafProducer flushes and closes the shared producer if it was created.
+func CloseKafProducer() {
+
+	kafkaProducerMu.Lock()
+	defer kafkaProducerMu.Unlock()
+
+	if kafkaProducerInstance != nil {
+		kafkaProducerInstance.Close()
+		kafkaProducerInstance = nil
+	}
+}
+
+// NewKafProducerFrom starts the delivery goroutine of producer.
+func NewKafProducerFrom(producer IRdProducer) *KafProducer {
+	p := &KafProducer{producer: producer, done: make(chan struct{}), counts: make(map[string]partitionCount)}
+	go p.deliveries()
+	return p
+}
+
 func NewProducer() (*kafka.Producer, error) {
 
-	cfg, err := CreateProducerConfig()
+	cfg, err := newProducerConfig()
 	if err != nil {
-		fmt.Printf("Failed to create producer: %s", err)
+		logger.Error("Failed to create producer config", "error", err)
 		return nil, err
 	}
 
 	producer, err := kafka.NewProducer(cfg)
 	if err != nil {
-		fmt.Printf("Failed to create producer: %s", err)
+		logger.Error("Failed to create producer", "error", err)
 		return nil, err
 	}
 
 	return producer, nil
 }
 
-func ProduceMessage(topic, key, headerMap string, data []byte) error {
-
-	producer, err := NewProducer()
+// newProducerConfig is the producer config with batching and a message
+// timeout below deliveryTimeout, so a message that cannot be delivered gets
+// its delivery report before Produce gives up waiting for it.
+func newProducerConfig() (*kafka.ConfigMap, error) {
+	cfg, err := CreateProducerConfig()
 	if err != nil {
-		return err
+		return nil, err
 	}
+	if err := cfg.SetKey("linger.ms", producerLingerMs); err != nil {
+		return nil, err
+	}
+	if err := cfg.SetKey("message.timeout.ms", int(producerMessageTimeout.Milliseconds())); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
 
-	defer producer.Close()
-
-	headers := []kafka.Header{}
-	if headerMap != "" {
-		headerList := strings.Split(headerMap, ",")
-		for _, val := range headerList {
-			res := strings.Split(val, "=")
-			header := kafka.Header{Key: res[0], Value: []byte(res[1])}
-			headers = append(headers, header)
+// deliveries hands every delivery report to the callback set as Opaque of
+// its message until the producer is closed.
+func (p *KafProducer) deliveries() {
+	defer close(p.done)
+	for ev := range p.producer.Events() {
+		switch e := ev.(type) {
+		case *kafka.Message:
+			done, ok := e.Opaque.(func(DeliveryResult))
+			if !ok {
+				continue
+			}
+			res := DeliveryResult{Partition: e.TopicPartition.Partition, Offset: int64(e.TopicPartition.Offset), Error: e.TopicPartition.Error}
+			if e.TopicPartition.Topic != nil {
+				res.Topic = *e.TopicPartition.Topic
+			}
+			done(res)
+		case kafka.Error:
+			logger.Error("Producer error", "error", e)
 		}
 	}
+}
+
+// NewMessage builds the message of spec. A fixed partition is checked
+// against the partition count of the topic, and with a partitioner the
+// partition of the key is computed here, so it can differ per message.
+func (p *KafProducer) NewMessage(spec MessageSpec) (*kafka.Message, error) {
 
-	kafkaMsg := func(topic string, key, data []byte, headers []kafka.Header) *kafka.Message {
-		return &kafka.Message{
-			TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
-			Key:            key,
-			Value:          data,
-			Headers:        headers,
+	if spec.Topic == "" {
+		return nil, errors.New("topic is required")
+	}
+	if spec.Partition < kafka.PartitionAny {
+		return nil, fmt.Errorf("invalid partition %d", spec.Partition)
+	}
+	if spec.Partition != kafka.PartitionAny && spec.Partitioner != "" {
+		return nil, errors.New("a partition and a partitioner cannot be combined")
+	}
+
+	partition := spec.Partition
+	if partition != kafka.PartitionAny || spec.Partitioner != "" {
+		count, err := p.partitionCount(spec.Topic)
+		if err != nil {
+			return nil, err
+		}
+		if partition >= count {
+			return nil, fmt.Errorf("topic '%s' has %d partitions, partition %d does not exist", spec.Topic, count, partition)
+		}
+		if spec.Partitioner != "" {
+			if partition, err = PartitionForKey(spec.Partitioner, spec.Key, count); err != nil {
+				return nil, err
+			}
 		}
 	}
 
-	deliveryCh := make(chan kafka.Event)
+	topic := spec.Topic
+	return &kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: partition},
+		Key:            spec.Key,
+		Value:          spec.Value,
+		Headers:        spec.Headers,
+		Timestamp:      spec.Timestamp,
+	}, nil
+}
+
+// partitionCount returns the partition count of topic, cached for
+// partitionCountTTL.
+func (p *KafProducer) partitionCount(topic string) (int32, error) {
+
+	p.countsMu.Lock()
+	defer p.countsMu.Unlock()
 
-	err = producer.Produce(kafkaMsg(topic, []byte(key), []byte(data), headers), deliveryCh)
+	if c, ok := p.counts[topic]; ok && time.Since(c.fetched) < partitionCountTTL {
+		return c.count, nil
+	}
+	metadata, err := p.producer.GetMetadata(&topic, false, metadataTimeoutMs)
 	if err != nil {
-		slog.Error("Error producing message")
+		return 0, fmt.Errorf("fetching metadata of topic '%s': %w", topic, err)
+	}
+	md, ok := metadata.Topics[topic]
+	if !ok || md.Error.Code() != kafka.ErrNoError || len(md.Partitions) == 0 {
+		return 0, fmt.Errorf("topic '%s' not found", topic)
+	}
+	count := int32(len(md.Partitions))
+	p.counts[topic] = partitionCount{count: count, fetched: time.Now()}
+	return count, nil
+}
+
+// ProduceAsync queues msg and returns once it is queued. done is called
+// from the delivery goroutine with the delivery report and must not block.
+// A full local queue is waited on instead of failing.
+func (p *KafProducer) ProduceAsync(msg *kafka.Message, done func(DeliveryResult)) error {
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return errors.New("producer is closed")
+	}
+
+	msg.Opaque = done
+	for {
+		err := p.producer.Produce(msg, nil)
+		if kafkaErr, ok := err.(kafka.Error); ok && kafkaErr.Code() == kafka.ErrQueueFull {
+			p.producer.Flush(producerQueueFullWaitMs)
+			continue
+		}
 		return err
 	}
+}
 
-	defer close(deliveryCh)
+// Produce sends msg and waits for its delivery report. The returned error
+// is the delivery error, if any.
+func (p *KafProducer) Produce(msg *kafka.Message) (DeliveryResult, error) {
 
-	// Wait for all messages to be delivered
-	//producer.Flush(2 * 100)
-	//producer.Close()
+	ch := make(chan DeliveryResult, 1)
+	if err := p.ProduceAsync(msg, func(res DeliveryResult) { ch <- res }); err != nil {
+		return DeliveryResult{}, err
+	}
 
 	select {
+	case res := <-ch:
+		return res, res.Error
+	case <-time.After(deliveryTimeout):
+		return DeliveryResult{}, errors.New("message delivery timed out")
+	}
+}
+
+// Flush waits up to timeout for queued messages to be delivered and returns
+// the number still queued.
+func (p *KafProducer) Flush(timeout time.Duration) int {
+	return p.producer.Flush(int(timeout.Milliseconds()))
+}
+
+// Close stops accepting messages and flushes the queued ones. Messages still
+// queued after producerFlushTimeout are purged, so every callback gets a
+// report, before the producer is closed.
+func (p *KafProducer) Close() {
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	p.mu.Unlock()
 
-	case event := <-deliveryCh:
-		msg := event.(*kafka.Message)
-		if msg.TopicPartition.Error != nil {
-			slog.Error("Failed to deliver message ", "error", msg.TopicPartition.Error)
-			return err
+	if remaining := p.Flush(producerFlushTimeout); remaining > 0 {
+		logger.Warn("Purging undelivered messages", "messages", remaining)
+		if err := p.producer.Purge(kafka.PurgeQueue | kafka.PurgeInFlight); err != nil {
+			logger.Error("Failed to purge messages", "error", err)
 		}
-		slog.Info("Published message to ", "topic", *msg.TopicPartition.Topic, "partition", msg.TopicPartition.Partition, "offset", msg.TopicPartition.Offset)
-	case <-time.After(10 * time.Second):
-		slog.Error("Message delivery timed out")
-		return fmt.Errorf("message delivery timed out")
+		p.producer.Flush(producerQueueFullWaitMs)
+	}
+	p.producer.Close()
+	<-p.done
+	logger.Info("Producer closed")
+}
+
+// ProduceMessage publishes one message through the shared producer and
+// waits for its delivery report.
+func ProduceMessage(spec MessageSpec) (DeliveryResult, error) {
+
+	producer, err := NewKafProducer()
+	if err != nil {
+		return DeliveryResult{}, err
+	}
+
+	msg, err := producer.NewMessage(spec)
+	if err != nil {
+		return DeliveryResult{}, err
+	}
+	res, err := producer.Produce(msg)
+	if err != nil {
+		logger.Error("Failed to deliver message", "topic", spec.Topic, "error", err)
+		return res, fmt.Errorf("failed to deliver message to topic '%s': %w", spec.Topic, err)
 	}
 
-	slog.Info("Message published successfully")
+	logger.Info("Published message", "topic", res.Topic, "partition", res.Partition, "offset", res.Offset)
+	return res, nil
+}
+
+// ProduceSummary counts the messages of a file produced through the shared
+// producer.
+type ProduceSummary struct {
+	InputFile  string
+	Read       int64
+	Delivered  int64
+	Failed     int64
+	Partitions map[string]map[int32]int64
+	// FirstError is the first delivery error, if any.
+	FirstError error
+	Elapsed    time.Duration
+}
+
+// ProduceLimits bounds how fast messages are produced. Rate is in messages
+// per second and InFlight caps the messages waiting for their delivery
+// report. Zero means no limit for both.
+type ProduceLimits struct {
+	Rate     float64
+	InFlight int
+}
+
+// produceRecords produces the message built from every record of reader and
+// waits for all delivery reports. Reading stops at the first read or build
+// error, or when ctx is done; messages already sent are still counted.
+// Observe, when not nil, is called with every delivery report after it was
+// counted.
+func produceRecords(ctx context.Context, source string, reader RecordReader,
+	build func(rec *MessageRecord) (*kafka.Message, error), limits ProduceLimits,
+	observe func(DeliveryResult)) (*ProduceSummary, error) {
+
+	producer, err := NewKafProducer()
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &ProduceSummary{InputFile: source, Partitions: make(map[string]map[int32]int64)}
+	startTime := time.Now()
+
+	var slots chan struct{}
+	if limits.InFlight > 0 {
+		slots = make(chan struct{}, limits.InFlight)
+	}
+	limiter := newRateLimiter(limits.Rate)
+
+	// Reports arrive on the single delivery goroutine of the producer.
+	var pending sync.WaitGroup
+	delivered := func(res DeliveryResult) {
+		defer pending.Done()
+		if slots != nil {
+			<-slots
+		}
+		if observe != nil {
+			defer observe(res)
+		}
+		if res.Error != nil {
+			summary.Failed++
+			if summary.FirstError == nil {
+				summary.FirstError = res.Error
+			}
+			return
+		}
+		summary.Delivered++
+		if summary.Partitions[res.Topic] == nil {
+			summary.Partitions[res.Topic] = make(map[int32]int64)
+		}
+		summary.Partitions[res.Topic][res.Partition]++
+	}
+
+	var readErr error
+	for readErr == nil {
+		rec, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			readErr = fmt.Errorf("failed to read record %d from %s: %w", summary.Read+1, source, err)
+			break
+		}
+		summary.Read++
+
+		msg, err := build(rec)
+		if err != nil {
+			readErr = fmt.Errorf("record %d: %w", summary.Read, err)
+			break
+		}
+
+		if err := limiter.Wait(ctx); err != nil {
+			readErr = fmt.Errorf("stopped before record %d: %w", summary.Read, err)
+			break
+		}
+		if slots != nil {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				readErr = fmt.Errorf("stopped before record %d: %w", summary.Read, ctx.Err())
+				continue
+			}
+		}
+
+		pending.Add(1)
+		if err := producer.ProduceAsync(msg, delivered); err != nil {
+			pending.Done()
+			if slots != nil {
+				<-slots
+			}
+			readErr = fmt.Errorf("failed to produce record %d: %w", summary.Read, err)
+		}
+	}
 
-	return nil
+	// Every produced message gets exactly one delivery report, even on timeout.
+	pending.Wait()
+	summary.Elapsed = time.Since(startTime)
+	return summary, readErr
 }
@@ -1,17 +1,141 @@
 package services
 
 import (
+	"errors"
 	"kafctl/internal/config"
+	"kafctl/internal/services/mocks"
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestPublish(t *testing.T) {
 
 }
 
+// deliverAt reports every produced message as delivered at offset, or failed
+// with err.
+func deliverAt(producer *mocks.MockProducer, offset kafka.Offset, err error) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		msg := args.Get(0).(*kafka.Message)
+		msg.TopicPartition.Partition = 2
+		msg.TopicPartition.Offset = offset
+		msg.TopicPartition.Error = err
+		producer.EventsChan <- msg
+	}
+}
+
+func Test_KafProducer_Produce(t *testing.T) {
+
+	rdProducer := mocks.NewMockProducer()
+	producer := NewKafProducerFrom(rdProducer)
+
+	topic := "orders"
+	rdProducer.On("Produce", mock.Anything, mock.Anything).Run(deliverAt(rdProducer, 42, nil)).Return(nil).Once()
+	res, err := producer.Produce(&kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny}})
+	assert.NoError(t, err)
+	assert.Equal(t, DeliveryResult{Topic: topic, Partition: 2, Offset: 42}, res)
+
+	rdProducer.On("Produce", mock.Anything, mock.Anything).Run(deliverAt(rdProducer, kafka.OffsetInvalid, errors.New("topic authorization failed"))).Return(nil).Once()
+	_, err = producer.Produce(&kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny}})
+	assert.ErrorContains(t, err, "topic authorization failed")
+
+	rdProducer.On("Flush", mock.Anything).Return(0)
+	rdProducer.On("Close").Return()
+	producer.Close()
+	rdProducer.AssertNotCalled(t, "Purge", mock.Anything)
+}
+
+func Test_NewProducerConfig(t *testing.T) {
+
+	cfg, err := newProducerConfig()
+	assert.NoError(t, err)
+	timeout, err := cfg.Get("message.timeout.ms", nil)
+	assert.NoError(t, err)
+	assert.Less(t, time.Duration(timeout.(int))*time.Millisecond, deliveryTimeout)
+	linger, err := cfg.Get("linger.ms", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, producerLingerMs, linger)
+}
+
+func Test_KafProducer_QueueFull(t *testing.T) {
+
+	rdProducer := mocks.NewMockProducer()
+	producer := NewKafProducerFrom(rdProducer)
+
+	topic := "orders"
+	rdProducer.On("Produce", mock.Anything, mock.Anything).Return(kafka.NewError(kafka.ErrQueueFull, "queue full", false)).Once()
+	rdProducer.On("Flush", producerQueueFullWaitMs).Return(1).Once()
+	rdProducer.On("Produce", mock.Anything, mock.Anything).Run(deliverAt(rdProducer, 7, nil)).Return(nil).Once()
+
+	delivered := make(chan DeliveryResult, 1)
+	err := producer.ProduceAsync(&kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny}},
+		func(res DeliveryResult) { delivered <- res })
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), (<-delivered).Offset)
+	rdProducer.AssertNumberOfCalls(t, "Produce", 2)
+}
+
+func Test_KafProducer_Close(t *testing.T) {
+
+	rdProducer := mocks.NewMockProducer()
+	producer := NewKafProducerFrom(rdProducer)
+
+	rdProducer.On("Flush", int(producerFlushTimeout.Milliseconds())).Return(3).Once()
+	rdProducer.On("Purge", kafka.PurgeQueue|kafka.PurgeInFlight).Return(nil).Once()
+	rdProducer.On("Flush", producerQueueFullWaitMs).Return(0).Once()
+	rdProducer.On("Close").Return().Once()
+
+	producer.Close()
+	producer.Close()
+	rdProducer.AssertExpectations(t)
+
+	topic := "orders"
+	err := producer.ProduceAsync(&kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic}}, func(DeliveryResult) {})
+	assert.ErrorContains(t, err, "producer is closed")
+	rdProducer.AssertNotCalled(t, "Produce", mock.Anything, mock.Anything)
+}
+
+func Test_KafProducer_NewMessage(t *testing.T) {
+
+	rdProducer := mocks.NewMockProducer()
+	producer := NewKafProducerFrom(rdProducer)
+
+	topic := "orders"
+	metadata := &kafka.Metadata{Topics: map[string]kafka.TopicMetadata{
+		topic: {Topic: topic, Partitions: make([]kafka.PartitionMetadata, 6)},
+	}}
+	rdProducer.On("GetMetadata", &topic, false, metadataTimeoutMs).Return(metadata, nil).Once()
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	msg, err := producer.NewMessage(MessageSpec{Topic: topic, Key: []byte("21"), Value: []byte("v"),
+		Partition: kafka.PartitionAny, Partitioner: PartitionerMurmur2, Timestamp: at})
+	assert.NoError(t, err)
+	assert.Equal(t, int32((-973932308&0x7fffffff)%6), msg.TopicPartition.Partition)
+	assert.Equal(t, at, msg.Timestamp)
+
+	// the partition count is cached
+	msg, err = producer.NewMessage(MessageSpec{Topic: topic, Partition: 5})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(5), msg.TopicPartition.Partition)
+	assert.Nil(t, msg.Key)
+
+	_, err = producer.NewMessage(MessageSpec{Topic: topic, Partition: 6})
+	assert.ErrorContains(t, err, "has 6 partitions")
+	_, err = producer.NewMessage(MessageSpec{Topic: topic, Partition: 1, Partitioner: PartitionerMurmur2})
+	assert.ErrorContains(t, err, "cannot be combined")
+	rdProducer.AssertNumberOfCalls(t, "GetMetadata", 1)
+
+	msg, err = producer.NewMessage(MessageSpec{Topic: topic, Partition: kafka.PartitionAny, Key: []byte{}})
+	assert.NoError(t, err)
+	assert.Equal(t, kafka.PartitionAny, msg.TopicPartition.Partition)
+	assert.Equal(t, []byte{}, msg.Key)
+}
+
 func ProduceMessageTest() error {
 
 	producer, err := NewProducer()
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"time"
+)
+
+// How far a rate limiter may fall behind before it stops catching up. Up to
+// this lag events are let through without waiting, so rates above the timer
+// resolution are still met on average.
+const rateLimiterMaxLag = 100 * time.Millisecond
+
+// rateLimiter spaces events out to a rate per second. A zero rate is not
+// limited.
+type rateLimiter struct {
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(rate float64) *rateLimiter {
+	if rate <= 0 {
+		return &rateLimiter{}
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / rate)}
+}
+
+// Wait blocks until the next event is due or ctx is done.
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	if l.interval == 0 {
+		return ctx.Err()
+	}
+
+	now := time.Now()
+	if now.Sub(l.next) > rateLimiterMaxLag {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	if wait <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
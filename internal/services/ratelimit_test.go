@@ -0,0 +1,33 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RateLimiter(t *testing.T) {
+
+	limiter := newRateLimiter(200)
+	start := time.Now()
+	for i := 0; i < 21; i++ {
+		assert.NoError(t, limiter.Wait(context.Background()))
+	}
+	// 20 intervals of 5ms
+	assert.GreaterOrEqual(t, time.Since(start), 95*time.Millisecond)
+
+	unlimited := newRateLimiter(0)
+	start = time.Now()
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, unlimited.Wait(context.Background()))
+	}
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	slow := newRateLimiter(0.1)
+	slow.Wait(ctx)
+	assert.ErrorIs(t, slow.Wait(ctx), context.Canceled)
+}
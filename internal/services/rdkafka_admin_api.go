@@ -17,6 +17,35 @@ type IRdAdminClient interface {
 		options ...kafka.DescribeTopicsAdminOption) (result kafka.DescribeTopicsResult, err error)
 	ListOffsets(ctx context.Context, topicPartitionOffsets map[kafka.TopicPartition]kafka.OffsetSpec,
 		options ...kafka.ListOffsetsAdminOption) (result kafka.ListOffsetsResult, err error)
+	ListConsumerGroups(ctx context.Context,
+		options ...kafka.ListConsumerGroupsAdminOption) (result kafka.ListConsumerGroupsResult, err error)
+	DescribeConsumerGroups(ctx context.Context, groups []string,
+		options ...kafka.DescribeConsumerGroupsAdminOption) (result kafka.DescribeConsumerGroupsResult, err error)
+	ListConsumerGroupOffsets(ctx context.Context, groupsPartitions []kafka.ConsumerGroupTopicPartitions,
+		options ...kafka.ListConsumerGroupOffsetsAdminOption) (lcgor kafka.ListConsumerGroupOffsetsResult, err error)
+	AlterConsumerGroupOffsets(ctx context.Context, groupsPartitions []kafka.ConsumerGroupTopicPartitions,
+		options ...kafka.AlterConsumerGroupOffsetsAdminOption) (acgor kafka.AlterConsumerGroupOffsetsResult, err error)
+	DeleteConsumerGroups(ctx context.Context, groups []string,
+		options ...kafka.DeleteConsumerGroupsAdminOption) (result kafka.DeleteConsumerGroupsResult, err error)
+	DescribeConfigs(ctx context.Context, resources []kafka.ConfigResource,
+		options ...kafka.DescribeConfigsAdminOption) (result []kafka.ConfigResourceResult, err error)
+	IncrementalAlterConfigs(ctx context.Context, resources []kafka.ConfigResource,
+		options ...kafka.AlterConfigsAdminOption) (result []kafka.ConfigResourceResult, err error)
+	CreatePartitions(ctx context.Context, partitions []kafka.PartitionsSpecification,
+		options ...kafka.CreatePartitionsAdminOption) (result []kafka.TopicResult, err error)
+	DescribeCluster(ctx context.Context, options ...kafka.DescribeClusterAdminOption) (result kafka.DescribeClusterResult, err error)
+	CreateACLs(ctx context.Context, aclBindings kafka.ACLBindings,
+		options ...kafka.CreateACLsAdminOption) (result []kafka.CreateACLResult, err error)
+	DescribeACLs(ctx context.Context, aclBindingFilter kafka.ACLBindingFilter,
+		options ...kafka.DescribeACLsAdminOption) (result *kafka.DescribeACLsResult, err error)
+	DeleteACLs(ctx context.Context, aclBindingFilters kafka.ACLBindingFilters,
+		options ...kafka.DeleteACLsAdminOption) (result []kafka.DeleteACLsResult, err error)
+	DescribeUserScramCredentials(ctx context.Context, users []string,
+		options ...kafka.DescribeUserScramCredentialsAdminOption) (result kafka.DescribeUserScramCredentialsResult, err error)
+	AlterUserScramCredentials(ctx context.Context, upsertions []kafka.UserScramCredentialUpsertion, deletions []kafka.UserScramCredentialDeletion,
+		options ...kafka.AlterUserScramCredentialsAdminOption) (result kafka.AlterUserScramCredentialsResult, err error)
+	DeleteRecords(ctx context.Context, recordsToDelete []kafka.TopicPartition,
+		options ...kafka.DeleteRecordsAdminOption) (result kafka.DeleteRecordsResults, err error)
 }
 
 type RdKafkaAdmin struct {
@@ -50,3 +79,79 @@ func (ka *RdKafkaAdmin) ListOffsets(ctx context.Context, topicPartitionOffsets m
 	options ...kafka.ListOffsetsAdminOption) (result kafka.ListOffsetsResult, err error) {
 	return ka.admin.ListOffsets(ctx, topicPartitionOffsets, options...)
 }
+
+func (ka *RdKafkaAdmin) ListConsumerGroups(ctx context.Context,
+	options ...kafka.ListConsumerGroupsAdminOption) (result kafka.ListConsumerGroupsResult, err error) {
+	return ka.admin.ListConsumerGroups(ctx, options...)
+}
+
+func (ka *RdKafkaAdmin) DescribeConsumerGroups(ctx context.Context, groups []string,
+	options ...kafka.DescribeConsumerGroupsAdminOption) (result kafka.DescribeConsumerGroupsResult, err error) {
+	return ka.admin.DescribeConsumerGroups(ctx, groups, options...)
+}
+
+func (ka *RdKafkaAdmin) ListConsumerGroupOffsets(ctx context.Context, groupsPartitions []kafka.ConsumerGroupTopicPartitions,
+	options ...kafka.ListConsumerGroupOffsetsAdminOption) (lcgor kafka.ListConsumerGroupOffsetsResult, err error) {
+	return ka.admin.ListConsumerGroupOffsets(ctx, groupsPartitions, options...)
+}
+
+func (ka *RdKafkaAdmin) AlterConsumerGroupOffsets(ctx context.Context, groupsPartitions []kafka.ConsumerGroupTopicPartitions,
+	options ...kafka.AlterConsumerGroupOffsetsAdminOption) (acgor kafka.AlterConsumerGroupOffsetsResult, err error) {
+	return ka.admin.AlterConsumerGroupOffsets(ctx, groupsPartitions, options...)
+}
+
+func (ka *RdKafkaAdmin) DeleteConsumerGroups(ctx context.Context, groups []string,
+	options ...kafka.DeleteConsumerGroupsAdminOption) (result kafka.DeleteConsumerGroupsResult, err error) {
+	return ka.admin.DeleteConsumerGroups(ctx, groups, options...)
+}
+
+func (ka *RdKafkaAdmin) DescribeConfigs(ctx context.Context, resources []kafka.ConfigResource,
+	options ...kafka.DescribeConfigsAdminOption) (result []kafka.ConfigResourceResult, err error) {
+	return ka.admin.DescribeConfigs(ctx, resources, options...)
+}
+
+func (ka *RdKafkaAdmin) IncrementalAlterConfigs(ctx context.Context, resources []kafka.ConfigResource,
+	options ...kafka.AlterConfigsAdminOption) (result []kafka.ConfigResourceResult, err error) {
+	return ka.admin.IncrementalAlterConfigs(ctx, resources, options...)
+}
+
+func (ka *RdKafkaAdmin) CreatePartitions(ctx context.Context, partitions []kafka.PartitionsSpecification,
+	options ...kafka.CreatePartitionsAdminOption) (result []kafka.TopicResult, err error) {
+	return ka.admin.CreatePartitions(ctx, partitions, options...)
+}
+
+func (ka *RdKafkaAdmin) DescribeCluster(ctx context.Context,
+	options ...kafka.DescribeClusterAdminOption) (result kafka.DescribeClusterResult, err error) {
+	return ka.admin.DescribeCluster(ctx, options...)
+}
+
+func (ka *RdKafkaAdmin) CreateACLs(ctx context.Context, aclBindings kafka.ACLBindings,
+	options ...kafka.CreateACLsAdminOption) (result []kafka.CreateACLResult, err error) {
+	return ka.admin.CreateACLs(ctx, aclBindings, options...)
+}
+
+func (ka *RdKafkaAdmin) DescribeACLs(ctx context.Context, aclBindingFilter kafka.ACLBindingFilter,
+	options ...kafka.DescribeACLsAdminOption) (result *kafka.DescribeACLsResult, err error) {
+	return ka.admin.DescribeACLs(ctx, aclBindingFilter, options...)
+}
+
+func (ka *RdKafkaAdmin) DeleteACLs(ctx context.Context, aclBindingFilters kafka.ACLBindingFilters,
+	options ...kafka.DeleteACLsAdminOption) (result []kafka.DeleteACLsResult, err error) {
+	return ka.admin.DeleteACLs(ctx, aclBindingFilters, options...)
+}
+
+func (ka *RdKafkaAdmin) DescribeUserScramCredentials(ctx context.Context, users []string,
+	options ...kafka.DescribeUserScramCredentialsAdminOption) (result kafka.DescribeUserScramCredentialsResult, err error) {
+	return ka.admin.DescribeUserScramCredentials(ctx, users, options...)
+}
+
+func (ka *RdKafkaAdmin) AlterUserScramCredentials(ctx context.Context, upsertions []kafka.UserScramCredentialUpsertion,
+	deletions []kafka.UserScramCredentialDeletion,
+	options ...kafka.AlterUserScramCredentialsAdminOption) (result kafka.AlterUserScramCredentialsResult, err error) {
+	return ka.admin.AlterUserScramCredentials(ctx, upsertions, deletions, options...)
+}
+
+func (ka *RdKafkaAdmin) DeleteRecords(ctx context.Context, recordsToDelete []kafka.TopicPartition,
+	options ...kafka.DeleteRecordsAdminOption) (result kafka.DeleteRecordsResults, err error) {
+	return ka.admin.DeleteRecords(ctx, recordsToDelete, options...)
+}
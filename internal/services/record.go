@@ -0,0 +1,110 @@
+package services
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// EncodingBase64 marks a record whose key, header values and value are base64
+// encoded because at least one of them is not valid UTF-8.
+const EncodingBase64 = "base64"
+
+// MessageRecord is the serializable form of a consumed message used by the
+// export and import formats. A nil Key, Value or header Value is a Kafka null.
+type MessageRecord struct {
+	Topic         string         `json:"topic"`
+	Partition     int32          `json:"partition"`
+	Offset        int64          `json:"offset"`
+	Timestamp     time.Time      `json:"timestamp"`
+	TimestampType string         `json:"timestampType"`
+	Key           *string        `json:"key"`
+	Headers       []RecordHeader `json:"headers"`
+	Value         *string        `json:"value"`
+	Encoding      string         `json:"encoding,omitempty"`
+}
+
+// RecordHeader is one message header. Keys can repeat and a nil Value is a
+// header without value. Encoding, when set, overrides the encoding of the
+// record for this value, see HeaderEncodings.
+type RecordHeader struct {
+	Key      string  `json:"key"`
+	Value    *string `json:"value"`
+	Encoding string  `json:"encoding,omitempty"`
+}
+
+// NewMessageRecord converts a consumed message into a MessageRecord.
+func NewMessageRecord(msg *kafka.Message) MessageRecord {
+
+	binary := !utf8.Valid(msg.Key) || !utf8.Valid(msg.Value)
+	for _, h := range msg.Headers {
+		binary = binary || !utf8.Valid(h.Value)
+	}
+
+	encode := func(data []byte) *string {
+		if data == nil {
+			return nil
+		}
+		s := string(data)
+		if binary {
+			s = base64.StdEncoding.EncodeToString(data)
+		}
+		return &s
+	}
+
+	rec := MessageRecord{
+		Partition:     msg.TopicPartition.Partition,
+		Offset:        int64(msg.TopicPartition.Offset),
+		Timestamp:     msg.Timestamp,
+		TimestampType: msg.TimestampType.String(),
+		Key:           encode(msg.Key),
+		Headers:       make([]RecordHeader, 0, len(msg.Headers)),
+		Value:         encode(msg.Value),
+	}
+	if msg.TopicPartition.Topic != nil {
+		rec.Topic = *msg.TopicPartition.Topic
+	}
+	for _, h := range msg.Headers {
+		rec.Headers = append(rec.Headers, RecordHeader{Key: h.Key, Value: encode(h.Value)})
+	}
+	if binary {
+		rec.Encoding = EncodingBase64
+	}
+	return rec
+}
+
+// decode returns the raw bytes of a key, value or header value of the record.
+func (r *MessageRecord) decode(s *string) ([]byte, error) {
+	if s == nil {
+		return nil, nil
+	}
+	switch r.Encoding {
+	case "":
+		return []byte(*s), nil
+	case EncodingBase64:
+		return base64.StdEncoding.DecodeString(*s)
+	default:
+		return nil, fmt.Errorf("unsupported record encoding %q", r.Encoding)
+	}
+}
+
+// KeyBytes returns the raw message key.
+func (r *MessageRecord) KeyBytes() ([]byte, error) {
+	return r.decode(r.Key)
+}
+
+// ValueBytes returns the raw message value.
+func (r *MessageRecord) ValueBytes() ([]byte, error) {
+	return r.decode(r.Value)
+}
+
+// KafkaHeaders returns the record headers with their raw values.
+func (r *MessageRecord) KafkaHeaders() ([]kafka.Header, error) {
+	if r.Encoding != "" && r.Encoding != EncodingBase64 {
+		return nil, fmt.Errorf("unsupported record encoding %q", r.Encoding)
+	}
+	return kafkaHeaders(r.Headers, r.Encoding)
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecordReader reads message records written by a RecordWriter.
+// Read returns io.EOF once all records are consumed.
+type RecordReader interface {
+	Read() (*MessageRecord, error)
+}
+
+// NewRecordReader returns a RecordReader for the given format.
+func NewRecordReader(format string, r io.Reader) (RecordReader, error) {
+	switch format {
+	case FormatJSONL:
+		return &jsonlReader{dec: json.NewDecoder(r)}, nil
+	case FormatJSON:
+		return &jsonArrayReader{dec: json.NewDecoder(r)}, nil
+	case FormatCSV:
+		return newCSVReader(r), nil
+	case FormatAvro:
+		return newAvroReader(r)
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected one of %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+type jsonlReader struct {
+	dec *json.Decoder
+}
+
+func (jr *jsonlReader) Read() (*MessageRecord, error) {
+	var rec MessageRecord
+	if err := jr.dec.Decode(&rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+type jsonArrayReader struct {
+	dec     *json.Decoder
+	started bool
+}
+
+func (jr *jsonArrayReader) Read() (*MessageRecord, error) {
+	if !jr.started {
+		tok, err := jr.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+			return nil, fmt.Errorf("expected a JSON array of records")
+		}
+		jr.started = true
+	}
+
+	if !jr.dec.More() {
+		return nil, io.EOF
+	}
+	var rec MessageRecord
+	if err := jr.dec.Decode(&rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+type csvReader struct {
+	r       *csv.Reader
+	columns map[string]int
+}
+
+func newCSVReader(r io.Reader) *csvReader {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	return &csvReader{r: cr}
+}
+
+func (cr *csvReader) Read() (*MessageRecord, error) {
+	if cr.columns == nil {
+		header, err := cr.r.Read()
+		if err != nil {
+			return nil, err
+		}
+		cr.columns = make(map[string]int, len(header))
+		for i, name := range header {
+			cr.columns[strings.TrimSpace(name)] = i
+		}
+	}
+
+	row, err := cr.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	line, _ := cr.r.FieldPos(0)
+
+	cell := func(name string) string {
+		if i, ok := cr.columns[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+	nullable := func(name string) *string {
+		i, ok := cr.columns[name]
+		if !ok || i >= len(row) || row[i] == CSVNull {
+			return nil
+		}
+		s := row[i]
+		if isCSVNull(s) {
+			s = s[1:]
+		}
+		return &s
+	}
+
+	rec := &MessageRecord{
+		Topic:         cell("topic"),
+		TimestampType: cell("timestampType"),
+		Key:           nullable("key"),
+		Value:         nullable("value"),
+		Encoding:      cell("encoding"),
+	}
+	if s := cell("partition"); s != "" {
+		p, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid partition %q", line, s)
+		}
+		rec.Partition = int32(p)
+	}
+	if s := cell("offset"); s != "" {
+		if rec.Offset, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return nil, fmt.Errorf("line %d: invalid offset %q", line, s)
+		}
+	}
+	if s := cell("timestamp"); s != "" {
+		if rec.Timestamp, err = time.Parse(time.RFC3339Nano, s); err != nil {
+			return nil, fmt.Errorf("line %d: invalid timestamp %q", line, s)
+		}
+	}
+	if s := cell("headers"); s != "" {
+		if err := json.Unmarshal([]byte(s), &rec.Headers); err != nil {
+			return nil, fmt.Errorf("line %d: invalid headers: %w", line, err)
+		}
+	}
+	return rec, nil
+}
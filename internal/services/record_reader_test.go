@@ -0,0 +1,127 @@
+package services
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readRecords(t *testing.T, format string, data []byte) []*MessageRecord {
+	r, err := NewRecordReader(format, bytes.NewReader(data))
+	assert.NoError(t, err)
+
+	var recs []*MessageRecord
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		if err != nil {
+			break
+		}
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+func Test_RecordRoundTrip(t *testing.T) {
+	msgs := testMessages()
+
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			recs := readRecords(t, format, writeRecords(t, format))
+			assert.Len(t, recs, len(msgs))
+
+			for i, rec := range recs {
+				expected := NewMessageRecord(msgs[i])
+				assert.Equal(t, expected.Topic, rec.Topic)
+				assert.Equal(t, expected.Partition, rec.Partition)
+				assert.Equal(t, expected.Offset, rec.Offset)
+				assert.True(t, expected.Timestamp.Equal(rec.Timestamp))
+				assert.Equal(t, expected.TimestampType, rec.TimestampType)
+
+				key, err := rec.KeyBytes()
+				assert.NoError(t, err)
+				assert.Equal(t, msgs[i].Key, key)
+				value, err := rec.ValueBytes()
+				assert.NoError(t, err)
+				assert.Equal(t, msgs[i].Value, value)
+				headers, err := rec.KafkaHeaders()
+				assert.NoError(t, err)
+				assert.Len(t, headers, len(msgs[i].Headers))
+			}
+		})
+	}
+}
+
+func Test_CSVNullRoundTrip(t *testing.T) {
+	str := func(s string) *string { return &s }
+	recs := []*MessageRecord{
+		{Topic: "Aatest-export", Key: nil, Value: str("")},
+		{Topic: "Aatest-export", Key: str(""), Value: nil},
+		{Topic: "Aatest-export", Key: str(`\N`), Value: str(`\\N`)},
+		{Topic: "Aatest-export", Key: str("N"), Value: str(`a\N`)},
+	}
+
+	var buf bytes.Buffer
+	w, err := NewRecordWriter(FormatCSV, &buf)
+	assert.NoError(t, err)
+	for _, rec := range recs {
+		assert.NoError(t, w.Write(rec))
+	}
+	assert.NoError(t, w.Close())
+	assert.Contains(t, buf.String(), `,\N,`)
+
+	got := readRecords(t, FormatCSV, buf.Bytes())
+	assert.Len(t, got, len(recs))
+	for i, rec := range got {
+		assert.Equal(t, recs[i].Key, rec.Key, "key %d", i)
+		assert.Equal(t, recs[i].Value, rec.Value, "value %d", i)
+	}
+
+	// a missing column is null as well
+	got = readRecords(t, FormatCSV, []byte("value\nv1\n"))
+	assert.Nil(t, got[0].Key)
+	assert.Equal(t, "v1", *got[0].Value)
+}
+
+func Test_AvroReaderManyBlocks(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewRecordWriter(FormatAvro, &buf)
+	assert.NoError(t, err)
+	for i := 0; i < avroBlockSize*2+5; i++ {
+		rec := NewMessageRecord(testMessages()[0])
+		rec.Offset = int64(i)
+		assert.NoError(t, w.Write(&rec))
+	}
+	assert.NoError(t, w.Close())
+
+	recs := readRecords(t, FormatAvro, buf.Bytes())
+	assert.Len(t, recs, avroBlockSize*2+5)
+	assert.Equal(t, int64(avroBlockSize*2+4), recs[len(recs)-1].Offset)
+
+	_, err = NewRecordReader(FormatAvro, bytes.NewReader([]byte("not avro")))
+	assert.Error(t, err)
+}
+
+func Test_ReplayMessage(t *testing.T) {
+	rec := NewMessageRecord(testMessages()[0])
+
+	msg, err := replayMessage(&rec, ReplayOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Aatest-export", *msg.TopicPartition.Topic)
+	assert.Equal(t, []byte("order-1"), msg.Key)
+	assert.Len(t, msg.Headers, 1)
+
+	msg, err = replayMessage(&rec, ReplayOptions{Topic: "Aatest-retry", KeepPartition: true, Provenance: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "Aatest-retry", *msg.TopicPartition.Topic)
+	assert.Equal(t, int32(1), msg.TopicPartition.Partition)
+	assert.Len(t, msg.Headers, 4)
+	assert.Equal(t, SourceOffsetHeader, msg.Headers[3].Key)
+	assert.Equal(t, strconv.Itoa(42), string(msg.Headers[3].Value))
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Export file formats.
+const (
+	FormatJSONL = "jsonl"
+	FormatJSON  = "json"
+	FormatCSV   = "csv"
+	FormatAvro  = "avro"
+)
+
+var Formats = []string{FormatJSONL, FormatJSON, FormatCSV, FormatAvro}
+
+// CSVNull marks a null key or value in a CSV cell. A text made of backslashes
+// and an N, such as CSVNull itself, is written with one more backslash.
+const CSVNull = `\N`
+
+// CSVColumns is the header row written by the CSV format. Null keys and values
+// are written as CSVNull, empty ones as empty cells; headers are a JSON array.
+var CSVColumns = []string{"topic", "partition", "offset", "timestamp", "timestampType", "key", "headers", "value", "encoding"}
+
+// RecordWriter writes message records in one export format.
+type RecordWriter interface {
+	Write(rec *MessageRecord) error
+	// Close completes the output, e.g. the closing bracket of a JSON array.
+	// It does not close the underlying writer.
+	Close() error
+}
+
+// NewRecordWriter returns a RecordWriter for the given format.
+func NewRecordWriter(format string, w io.Writer) (RecordWriter, error) {
+	switch format {
+	case FormatJSONL:
+		return &jsonlWriter{enc: json.NewEncoder(w)}, nil
+	case FormatJSON:
+		return &jsonArrayWriter{w: w}, nil
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case FormatAvro:
+		return newAvroWriter(w)
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected one of %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+// FormatFromFileName guesses the export format from a file extension and
+// falls back to JSON Lines.
+func FormatFromFileName(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return FormatJSON
+	case ".csv":
+		return FormatCSV
+	case ".avro":
+		return FormatAvro
+	default:
+		return FormatJSONL
+	}
+}
+
+type jsonlWriter struct {
+	enc *json.Encoder
+}
+
+func (jw *jsonlWriter) Write(rec *MessageRecord) error {
+	return jw.enc.Encode(rec)
+}
+
+func (jw *jsonlWriter) Close() error {
+	return nil
+}
+
+type jsonArrayWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (jw *jsonArrayWriter) Write(rec *MessageRecord) error {
+	data, err := json.MarshalIndent(rec, "  ", "  ")
+	if err != nil {
+		return err
+	}
+
+	sep := ",\n  "
+	if jw.count == 0 {
+		sep = "[\n  "
+	}
+	if _, err := io.WriteString(jw.w, sep); err != nil {
+		return err
+	}
+	jw.count++
+	_, err = jw.w.Write(data)
+	return err
+}
+
+func (jw *jsonArrayWriter) Close() error {
+	end := "\n]\n"
+	if jw.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(jw.w, end)
+	return err
+}
+
+type csvWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (cw *csvWriter) Write(rec *MessageRecord) error {
+	if !cw.headerWritten {
+		if err := cw.w.Write(CSVColumns); err != nil {
+			return err
+		}
+		cw.headerWritten = true
+	}
+
+	headers, err := json.Marshal(rec.Headers)
+	if err != nil {
+		return err
+	}
+
+	row := []string{
+		rec.Topic,
+		strconv.FormatInt(int64(rec.Partition), 10),
+		strconv.FormatInt(rec.Offset, 10),
+		rec.Timestamp.Format(time.RFC3339Nano),
+		rec.TimestampType,
+		csvCell(rec.Key),
+		string(headers),
+		csvCell(rec.Value),
+		rec.Encoding,
+	}
+	return cw.w.Write(row)
+}
+
+func (cw *csvWriter) Close() error {
+	if !cw.headerWritten {
+		if err := cw.w.Write(CSVColumns); err != nil {
+			return err
+		}
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func csvCell(s *string) string {
+	switch {
+	case s == nil:
+		return CSVNull
+	case isCSVNull(*s):
+		return `\` + *s
+	}
+	return *s
+}
+
+// isCSVNull reports whether s is an N after one or more backslashes.
+func isCSVNull(s string) bool {
+	return len(s) > 1 && strings.TrimLeft(s, `\`) == "N"
+}
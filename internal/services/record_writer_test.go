@@ -0,0 +1,112 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+func testMessages() []*kafka.Message {
+	topic := "Aatest-export"
+	ts := time.Date(2024, 5, 1, 2, 3, 4, 0, time.UTC)
+	return []*kafka.Message{
+		{
+			TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 1, Offset: 42},
+			Key:            []byte("order-1"),
+			Value:          []byte(`{"status":"FAILED"}`),
+			Headers:        []kafka.Header{{Key: "traceId", Value: []byte("x-1")}},
+			Timestamp:      ts,
+			TimestampType:  kafka.TimestampCreateTime,
+		},
+		{
+			TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 0, Offset: 7},
+			Value:          []byte{0xff, 0x00, 0x01},
+			Timestamp:      ts,
+			TimestampType:  kafka.TimestampLogAppendTime,
+		},
+	}
+}
+
+func writeRecords(t *testing.T, format string) []byte {
+	var buf bytes.Buffer
+	w, err := NewRecordWriter(format, &buf)
+	assert.NoError(t, err)
+	for _, msg := range testMessages() {
+		rec := NewMessageRecord(msg)
+		assert.NoError(t, w.Write(&rec))
+	}
+	assert.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func Test_NewMessageRecord(t *testing.T) {
+	msgs := testMessages()
+
+	rec := NewMessageRecord(msgs[0])
+	assert.Equal(t, "Aatest-export", rec.Topic)
+	assert.Equal(t, "CreateTime", rec.TimestampType)
+	assert.Equal(t, "order-1", *rec.Key)
+	assert.Equal(t, "", rec.Encoding)
+
+	bin := NewMessageRecord(msgs[1])
+	assert.Nil(t, bin.Key)
+	assert.Equal(t, EncodingBase64, bin.Encoding)
+	value, err := bin.ValueBytes()
+	assert.NoError(t, err)
+	assert.Equal(t, msgs[1].Value, value)
+}
+
+func Test_JSONLWriter(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(string(writeRecords(t, FormatJSONL))), "\n")
+	assert.Len(t, lines, 2)
+
+	var rec MessageRecord
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &rec))
+	assert.Equal(t, int64(42), rec.Offset)
+	assert.Equal(t, "x-1", *rec.Headers[0].Value)
+}
+
+func Test_JSONArrayWriter(t *testing.T) {
+	var recs []MessageRecord
+	assert.NoError(t, json.Unmarshal(writeRecords(t, FormatJSON), &recs))
+	assert.Len(t, recs, 2)
+	assert.Equal(t, int32(0), recs[1].Partition)
+
+	var buf bytes.Buffer
+	w, err := NewRecordWriter(FormatJSON, &buf)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func Test_CSVWriter(t *testing.T) {
+	rows, err := csv.NewReader(bytes.NewReader(writeRecords(t, FormatCSV))).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 3)
+	assert.Equal(t, CSVColumns, rows[0])
+	assert.Equal(t, "order-1", rows[1][5])
+	assert.Equal(t, CSVNull, rows[2][5])
+	assert.Equal(t, EncodingBase64, rows[2][8])
+}
+
+func Test_AvroWriterHeader(t *testing.T) {
+	data := writeRecords(t, FormatAvro)
+	assert.True(t, bytes.HasPrefix(data, avroMagic))
+	assert.Contains(t, string(data), `"name":"MessageRecord"`)
+}
+
+func Test_FormatFromFileName(t *testing.T) {
+	assert.Equal(t, FormatJSON, FormatFromFileName("consumer_out.json"))
+	assert.Equal(t, FormatCSV, FormatFromFileName("out.CSV"))
+	assert.Equal(t, FormatAvro, FormatFromFileName("dlq.avro"))
+	assert.Equal(t, FormatJSONL, FormatFromFileName("kaf_output.txt"))
+
+	_, err := NewRecordWriter("xml", &bytes.Buffer{})
+	assert.Error(t, err)
+}
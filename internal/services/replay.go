@@ -0,0 +1,106 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"kafctl/internal/logger"
+	"os"
+	"strconv"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// Headers added to replayed messages when provenance is requested.
+const (
+	SourceTopicHeader     = "kafctl.source.topic"
+	SourcePartitionHeader = "kafctl.source.partition"
+	SourceOffsetHeader    = "kafctl.source.offset"
+)
+
+// ReplayOptions controls re-publishing an exported file.
+// An empty Topic sends each record back to the topic it was exported from,
+// an empty Format is derived from the input file name.
+type ReplayOptions struct {
+	InputFile string
+	Format    string
+	Topic     string
+	// KeepPartition produces each record to the partition it was exported from.
+	KeepPartition bool
+	// Provenance adds the source topic, partition and offset as headers.
+	Provenance bool
+}
+
+// ReplayMessages reads records from a kafctl export file and produces them
+// again with their keys and headers through the shared producer.
+func ReplayMessages(opts ReplayOptions) (*ProduceSummary, error) {
+
+	format := opts.Format
+	if format == "" {
+		format = FormatFromFileName(opts.InputFile)
+	}
+
+	file, err := os.Open(opts.InputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open input file %s: %w", opts.InputFile, err)
+	}
+	defer file.Close()
+
+	reader, err := NewRecordReader(format, bufio.NewReader(file))
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := produceRecords(context.Background(), opts.InputFile, reader, func(rec *MessageRecord) (*kafka.Message, error) {
+		return replayMessage(rec, opts)
+	}, ProduceLimits{}, nil)
+	if summary != nil {
+		logger.Info("Replay finished", "file", opts.InputFile, "read", summary.Read,
+			"delivered", summary.Delivered, "failed", summary.Failed, "elapsed", summary.Elapsed)
+	}
+	return summary, err
+}
+
+// replayMessage builds the message to produce for an exported record.
+func replayMessage(rec *MessageRecord, opts ReplayOptions) (*kafka.Message, error) {
+	key, err := rec.KeyBytes()
+	if err != nil {
+		return nil, err
+	}
+	value, err := rec.ValueBytes()
+	if err != nil {
+		return nil, err
+	}
+	headers, err := rec.KafkaHeaders()
+	if err != nil {
+		return nil, err
+	}
+
+	topic := opts.Topic
+	if topic == "" {
+		topic = rec.Topic
+	}
+	if topic == "" {
+		return nil, fmt.Errorf("no target topic given and the record has no source topic")
+	}
+
+	partition := kafka.PartitionAny
+	if opts.KeepPartition {
+		partition = rec.Partition
+	}
+
+	if opts.Provenance {
+		headers = append(headers,
+			kafka.Header{Key: SourceTopicHeader, Value: []byte(rec.Topic)},
+			kafka.Header{Key: SourcePartitionHeader, Value: []byte(strconv.FormatInt(int64(rec.Partition), 10))},
+			kafka.Header{Key: SourceOffsetHeader, Value: []byte(strconv.FormatInt(rec.Offset, 10))},
+		)
+	}
+
+	return &kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: partition},
+		Key:            key,
+		Value:          value,
+		Headers:        headers,
+	}, nil
+}
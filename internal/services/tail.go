@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"kafctl/internal/logger"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// TailOptions controls following a topic.
+type TailOptions struct {
+	// Partitions to follow, all partitions when empty
+	Partitions []int32
+	// Last starts this many offsets before the end of each partition, 0
+	// starts with the next message produced
+	Last int64
+	// Filter selects the messages passed on, nil passes all
+	Filter *MessageFilter
+}
+
+// Tail follows a topic from the current end of its partitions and passes
+// every new message matching the filter to handle until ctx is cancelled,
+// which returns nil. Handle blocking holds up polling, so a slow reader
+// slows down fetching instead of buffering without bound.
+func (c *Consumer) Tail(ctx context.Context, topic string, opts TailOptions, handle func(msg *kafka.Message) error) error {
+
+	window := NewMessageWindow()
+	window.Partitions = opts.Partitions
+	ranges, err := c.resolveRanges(topic, window)
+	if err != nil {
+		return err
+	}
+
+	assignments := make([]kafka.TopicPartition, 0, len(ranges))
+	for _, p := range ranges {
+		assignments = append(assignments, kafka.TopicPartition{
+			Topic:     &topic,
+			Partition: p.Partition,
+			Offset:    kafka.Offset(tailStartOffset(p.StartOffset, p.EndOffset, opts.Last)),
+		})
+	}
+	if err := c.consumer.Assign(assignments); err != nil {
+		return fmt.Errorf("failed to assign partitions: %w", err)
+	}
+	defer c.consumer.Unassign()
+
+	logger.Info("Tailing topic", "topic", topic, "partitions", len(assignments), "filter", opts.Filter)
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Stopped tailing topic", "topic", topic)
+			return nil
+		default:
+		}
+
+		switch e := c.consumer.Poll(pollTimeoutMs).(type) {
+		case *kafka.Message:
+			if opts.Filter != nil && !opts.Filter.Match(e) {
+				continue
+			}
+			if err := handle(e); err != nil {
+				return err
+			}
+
+		case kafka.Error:
+			if e.IsFatal() {
+				return fmt.Errorf("fatal consumer error: %w", e)
+			}
+			logger.Warn("Non-fatal consumer error", "error", e)
+		}
+	}
+}
+
+// tailStartOffset is the first offset tailed of a partition between the low
+// and high watermark: the high watermark for last 0, else last offsets before
+// it but not before the low watermark.
+func tailStartOffset(lowWm, highWm, last int64) int64 {
+	if last <= 0 {
+		return highWm
+	}
+	return max(lowWm, highWm-last)
+}
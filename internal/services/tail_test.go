@@ -0,0 +1,27 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TailStartOffset(t *testing.T) {
+
+	// the partition holds offsets 100 to 149
+	tests := []struct {
+		name string
+		last int64
+		want int64
+	}{
+		{"next message produced", 0, 150},
+		{"last within backlog", 10, 140},
+		{"whole backlog", 50, 100},
+		{"last beyond backlog", 500, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tailStartOffset(100, 150, tt.last))
+		})
+	}
+}
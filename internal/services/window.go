@@ -0,0 +1,396 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"kafctl/internal/logger"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+const (
+	// Give up when no message or EOF arrives for this long
+	rangeIdleTimeout = 30 * time.Second
+	// How often progress is reported while reading ranges
+	rangeProgressInterval = time.Second
+)
+
+// OffsetUnset leaves an offset bound of a MessageWindow open.
+const OffsetUnset int64 = -1
+
+// MessageWindow selects a bounded range of messages of a topic. Unset bounds
+// default to the low and high watermark captured when reading starts.
+type MessageWindow struct {
+	// Partitions to read, all partitions when empty
+	Partitions []int32
+	// FromOffset and ToOffset are inclusive, OffsetUnset when not set
+	FromOffset int64
+	ToOffset   int64
+	// FromTime is inclusive and ToTime exclusive, zero when not set
+	FromTime time.Time
+	ToTime   time.Time
+	// Last keeps only the last Last offsets of each partition, 0 means all
+	Last int64
+	// Filter selects the messages returned from the range, nil returns all
+	Filter *MessageFilter
+	// Limit caps the number of messages returned, 0 means no limit
+	Limit int
+	// LastPerPartition keeps only the last messages returned from each
+	// partition, 0 means all
+	LastPerPartition int
+}
+
+// NewMessageWindow returns a window covering all partitions up to the
+// current end of the topic.
+func NewMessageWindow() MessageWindow {
+	return MessageWindow{FromOffset: OffsetUnset, ToOffset: OffsetUnset}
+}
+
+// IsBounded reports whether any offset or time bound is set.
+func (w MessageWindow) IsBounded() bool {
+	return w.FromOffset != OffsetUnset || w.ToOffset != OffsetUnset || !w.FromTime.IsZero() || !w.ToTime.IsZero() || w.Last > 0
+}
+
+// PartitionRange is the offset range [StartOffset, EndOffset) read from one
+// partition, the number of messages scanned and the number returned from it.
+type PartitionRange struct {
+	Partition   int32
+	StartOffset int64
+	EndOffset   int64
+	Scanned     int64
+	Count       int64
+}
+
+type WindowResult struct {
+	Topic      string
+	Messages   []*kafka.Message
+	Partitions []PartitionRange
+	// Truncated is set when the window held more messages than its Limit or
+	// a partition more than LastPerPartition.
+	Truncated bool
+}
+
+// RangeProgress reports how far reading the partition ranges has got.
+// Read counts the messages scanned, Matched those that passed the filter.
+type RangeProgress struct {
+	Read               int64
+	Matched            int64
+	Total              int64
+	PartitionsDone     int
+	PartitionsAssigned int
+}
+
+// partitionState tracks one partition range while it is read.
+type partitionState struct {
+	PartitionRange
+	done bool
+}
+
+// errStopReading is returned by a range handler to end reading early.
+var errStopReading = errors.New("stop reading")
+
+var windowTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseWindowTime parses a window bound given as RFC 3339, as a date and time
+// without zone (taken as UTC) or as Unix milliseconds.
+func ParseWindowTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if millis, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.UnixMilli(millis).UTC(), nil
+	}
+	for _, layout := range windowTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, expected RFC 3339, 'YYYY-MM-DD hh:mm:ss' (UTC) or Unix milliseconds", s)
+}
+
+// ConsumeWindow reads exactly the messages of the window, sorted by
+// partition and offset, together with the count read from each partition.
+func (c *Consumer) ConsumeWindow(topic string, window MessageWindow) (*WindowResult, error) {
+
+	logger.Info("Consuming window", "topic", topic, "window", window)
+
+	ranges, err := c.resolveRanges(topic, window)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &WindowResult{Topic: topic}
+	last := map[int32]*lastMessages{}
+	err = c.readRanges(topic, ranges, window.Filter, func(msg *kafka.Message) error {
+		if window.LastPerPartition > 0 {
+			p := msg.TopicPartition.Partition
+			if last[p] == nil {
+				last[p] = &lastMessages{size: window.LastPerPartition}
+			}
+			if last[p].add(msg) {
+				result.Truncated = true
+			}
+			return nil
+		}
+		if window.Limit > 0 && len(result.Messages) >= window.Limit {
+			result.Truncated = true
+			return errStopReading
+		}
+		result.Messages = append(result.Messages, msg)
+		return nil
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range last {
+		result.Messages = append(result.Messages, l.msgs...)
+	}
+
+	sort.SliceStable(result.Messages, func(i, j int) bool {
+		a, b := result.Messages[i].TopicPartition, result.Messages[j].TopicPartition
+		if a.Partition != b.Partition {
+			return a.Partition < b.Partition
+		}
+		return a.Offset < b.Offset
+	})
+	result.Partitions = sortedRanges(ranges)
+
+	logger.Info("Window consumed", "topic", topic, "messages", len(result.Messages), "filter", window.Filter, "truncated", result.Truncated)
+	return result, nil
+}
+
+// lastMessages is a ring buffer holding the last size messages added.
+type lastMessages struct {
+	size int
+	msgs []*kafka.Message
+	next int
+}
+
+// add keeps msg and reports whether an older message was dropped for it.
+func (l *lastMessages) add(msg *kafka.Message) bool {
+	if len(l.msgs) < l.size {
+		l.msgs = append(l.msgs, msg)
+		return false
+	}
+	l.msgs[l.next] = msg
+	l.next = (l.next + 1) % l.size
+	return true
+}
+
+// resolveRanges captures the watermarks of the selected partitions and turns
+// the window bounds into an offset range for each of them.
+func (c *Consumer) resolveRanges(topic string, window MessageWindow) (map[int32]*partitionState, error) {
+
+	metadata, err := c.consumer.GetMetadata(&topic, false, metadataTimeoutMs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata for topic %s: %w", topic, err)
+	}
+
+	topicMetadata, ok := metadata.Topics[topic]
+	if !ok {
+		return nil, fmt.Errorf("topic '%s' not found in metadata", topic)
+	}
+	if topicMetadata.Error.Code() != kafka.ErrNoError {
+		kafkaErr := kafka.NewError(topicMetadata.Error.Code(), "", false)
+		return nil, fmt.Errorf("error in metadata for topic '%s': %w", topic, kafkaErr)
+	}
+
+	for _, p := range window.Partitions {
+		if !slices.ContainsFunc(topicMetadata.Partitions, func(pm kafka.PartitionMetadata) bool { return pm.ID == p }) {
+			return nil, fmt.Errorf("topic '%s' has no partition %d", topic, p)
+		}
+	}
+
+	ranges := make(map[int32]*partitionState)
+	for _, pMeta := range topicMetadata.Partitions {
+		if len(window.Partitions) > 0 && !slices.Contains(window.Partitions, pMeta.ID) {
+			continue
+		}
+
+		lowWm, highWm, err := c.consumer.QueryWatermarkOffsets(topic, pMeta.ID, metadataTimeoutMs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query watermark offsets for %s [%d]: %w", topic, pMeta.ID, err)
+		}
+
+		start, end := lowWm, highWm
+		if window.FromOffset != OffsetUnset {
+			start = max(start, window.FromOffset)
+		}
+		if window.ToOffset != OffsetUnset {
+			end = min(end, window.ToOffset+1)
+		}
+		if window.Last > 0 {
+			start = max(start, end-window.Last)
+		}
+		if !window.FromTime.IsZero() {
+			offset, err := c.offsetForTime(topic, pMeta.ID, window.FromTime, highWm)
+			if err != nil {
+				return nil, err
+			}
+			start = max(start, offset)
+		}
+		if !window.ToTime.IsZero() {
+			offset, err := c.offsetForTime(topic, pMeta.ID, window.ToTime, highWm)
+			if err != nil {
+				return nil, err
+			}
+			end = min(end, offset)
+		}
+		if end < start {
+			end = start
+		}
+
+		logger.Debug("Resolved partition range", "partition", pMeta.ID, "low", lowWm, "high", highWm, "start", start, "end", end)
+		ranges[pMeta.ID] = &partitionState{
+			PartitionRange: PartitionRange{Partition: pMeta.ID, StartOffset: start, EndOffset: end},
+			done:           end <= start,
+		}
+	}
+	return ranges, nil
+}
+
+// offsetForTime returns the first offset with a timestamp at or after ts, or
+// highWm when there is none.
+func (c *Consumer) offsetForTime(topic string, partition int32, ts time.Time, highWm int64) (int64, error) {
+	offsets, err := c.consumer.OffsetsForTimes([]kafka.TopicPartition{{
+		Topic:     &topic,
+		Partition: partition,
+		Offset:    kafka.Offset(ts.UnixMilli()),
+	}}, metadataTimeoutMs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up offset for time %s in %s [%d]: %w", ts, topic, partition, err)
+	}
+	if len(offsets) > 0 && offsets[0].Error != nil {
+		return 0, fmt.Errorf("failed to look up offset for time %s in %s [%d]: %w", ts, topic, partition, offsets[0].Error)
+	}
+	if len(offsets) == 0 || offsets[0].Offset < 0 {
+		return highWm, nil
+	}
+	return int64(offsets[0].Offset), nil
+}
+
+// readRanges assigns the partition ranges and passes every message inside
+// them that matches the filter to handle, until all partitions reach their
+// end offset or EOF. The handler may return errStopReading to end early.
+func (c *Consumer) readRanges(topic string, ranges map[int32]*partitionState, filter *MessageFilter,
+	handle func(msg *kafka.Message) error, progressFn func(RangeProgress)) error {
+
+	assignments := make([]kafka.TopicPartition, 0, len(ranges))
+	var total int64
+	for _, p := range ranges {
+		if p.done {
+			continue
+		}
+		assignments = append(assignments, kafka.TopicPartition{
+			Topic:     &topic,
+			Partition: p.Partition,
+			Offset:    kafka.Offset(p.StartOffset),
+		})
+		total += p.EndOffset - p.StartOffset
+	}
+
+	progress := func() {
+		if progressFn == nil {
+			return
+		}
+		res := RangeProgress{Total: total, PartitionsAssigned: len(assignments)}
+		for _, p := range ranges {
+			res.Read += p.Scanned
+			res.Matched += p.Count
+			if p.done && p.EndOffset > p.StartOffset {
+				res.PartitionsDone++
+			}
+		}
+		progressFn(res)
+	}
+
+	if len(assignments) == 0 {
+		progress()
+		return nil
+	}
+
+	if err := c.consumer.Assign(assignments); err != nil {
+		return fmt.Errorf("failed to assign partitions: %w", err)
+	}
+	defer c.consumer.Unassign()
+
+	remaining := len(assignments)
+	lastReport := time.Now()
+	lastActivity := time.Now()
+
+	for remaining > 0 {
+		if time.Since(lastActivity) > rangeIdleTimeout {
+			progress()
+			return fmt.Errorf("no messages received for %s, %d partition(s) not completed", rangeIdleTimeout, remaining)
+		}
+
+		switch e := c.consumer.Poll(pollTimeoutMs).(type) {
+		case *kafka.Message:
+			lastActivity = time.Now()
+			p, ok := ranges[e.TopicPartition.Partition]
+			if !ok || p.done {
+				continue
+			}
+			if int64(e.TopicPartition.Offset) >= p.EndOffset {
+				p.done = true
+				remaining--
+				continue
+			}
+			p.Scanned++
+			if filter == nil || filter.Match(e) {
+				if err := handle(e); errors.Is(err, errStopReading) {
+					progress()
+					return nil
+				} else if err != nil {
+					return err
+				}
+				p.Count++
+			}
+			if int64(e.TopicPartition.Offset) >= p.EndOffset-1 {
+				p.done = true
+				remaining--
+			}
+
+		case kafka.PartitionEOF:
+			lastActivity = time.Now()
+			if p, ok := ranges[e.Partition]; ok && !p.done {
+				logger.Debug("Reached end of partition", "partition", e.Partition, "offset", e.Offset)
+				p.done = true
+				remaining--
+			}
+
+		case kafka.Error:
+			if e.IsFatal() {
+				return fmt.Errorf("fatal consumer error: %w", e)
+			}
+			logger.Warn("Non-fatal consumer error", "error", e)
+		}
+
+		if time.Since(lastReport) >= rangeProgressInterval {
+			progress()
+			lastReport = time.Now()
+		}
+	}
+
+	progress()
+	return nil
+}
+
+func sortedRanges(ranges map[int32]*partitionState) []PartitionRange {
+	res := make([]PartitionRange, 0, len(ranges))
+	for _, p := range ranges {
+		res = append(res, p.PartitionRange)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Partition < res[j].Partition })
+	return res
+}
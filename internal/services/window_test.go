@@ -0,0 +1,59 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseWindowTime(t *testing.T) {
+	want := time.Date(2024, 5, 1, 2, 3, 4, 0, time.UTC)
+
+	for _, s := range []string{"2024-05-01T02:03:04Z", "2024-05-01T04:03:04+02:00", "2024-05-01T02:03:04",
+		"2024-05-01 02:03:04", "1714528984000"} {
+		got, err := ParseWindowTime(s)
+		assert.NoError(t, err, s)
+		assert.True(t, want.Equal(got), s)
+	}
+
+	got, err := ParseWindowTime("2024-05-01T02:03")
+	assert.NoError(t, err)
+	assert.Equal(t, want.Truncate(time.Minute), got)
+
+	_, err = ParseWindowTime("yesterday")
+	assert.Error(t, err)
+}
+
+func Test_MessageWindowIsBounded(t *testing.T) {
+	window := NewMessageWindow()
+	assert.False(t, window.IsBounded())
+
+	window.Partitions = []int32{0}
+	window.Limit = 10
+	assert.False(t, window.IsBounded())
+
+	window.FromOffset = 0
+	assert.True(t, window.IsBounded())
+
+	window = NewMessageWindow()
+	window.ToTime = time.Now()
+	assert.True(t, window.IsBounded())
+}
+
+func Test_LastMessages(t *testing.T) {
+	last := &lastMessages{size: 3}
+	var dropped []bool
+	for offset := range 5 {
+		msg := &kafka.Message{TopicPartition: kafka.TopicPartition{Offset: kafka.Offset(offset)}}
+		dropped = append(dropped, last.add(msg))
+	}
+	assert.Equal(t, []bool{false, false, false, true, true}, dropped)
+
+	var offsets []kafka.Offset
+	for _, msg := range last.msgs {
+		offsets = append(offsets, msg.TopicPartition.Offset)
+	}
+	assert.ElementsMatch(t, []kafka.Offset{2, 3, 4}, offsets)
+}